@@ -0,0 +1,259 @@
+// Package clics converts the contests to the objects of the ICPC Contest API (CLICS), used by
+// resolvers, contest data servers and balloon printers.
+package clics
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"online_judge/JudgeServer/common"
+	"online_judge/JudgeServer/model"
+	"online_judge/JudgeServer/scoreboard"
+)
+
+type Contest struct {
+	ID                       string  `json:"id"`
+	Name                     string  `json:"name"`
+	FormalName               string  `json:"formal_name"`
+	StartTime                string  `json:"start_time"`
+	Duration                 string  `json:"duration"`
+	ScoreboardFreezeDuration *string `json:"scoreboard_freeze_duration"`
+	ScoreboardType           string  `json:"scoreboard_type"`
+	PenaltyTime              int     `json:"penalty_time"`
+}
+
+type JudgementType struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Penalty bool   `json:"penalty"`
+	Solved  bool   `json:"solved"`
+}
+
+type Problem struct {
+	ID            string  `json:"id"`
+	Label         string  `json:"label"`
+	Name          string  `json:"name"`
+	Ordinal       int     `json:"ordinal"`
+	TimeLimit     float64 `json:"time_limit"`
+	TestDataCount int     `json:"test_data_count"`
+}
+
+type Team struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type Submission struct {
+	ID          string `json:"id"`
+	LanguageID  string `json:"language_id"`
+	ProblemID   string `json:"problem_id"`
+	TeamID      string `json:"team_id"`
+	Time        string `json:"time"`
+	ContestTime string `json:"contest_time"`
+}
+
+// Judgement is the judgement of a submission, its type is null until the submission is judged.
+type Judgement struct {
+	ID               string  `json:"id"`
+	SubmissionID     string  `json:"submission_id"`
+	JudgementTypeID  *string `json:"judgement_type_id"`
+	StartTime        string  `json:"start_time"`
+	StartContestTime string  `json:"start_contest_time"`
+	EndTime          *string `json:"end_time"`
+	EndContestTime   *string `json:"end_contest_time"`
+}
+
+type ScoreboardScore struct {
+	NumSolved int `json:"num_solved"`
+	TotalTime int `json:"total_time"`
+}
+
+type ScoreboardProblem struct {
+	ProblemID    string `json:"problem_id"`
+	NumJudged    int    `json:"num_judged"`
+	NumPending   int    `json:"num_pending"`
+	Solved       bool   `json:"solved"`
+	Time         *int   `json:"time,omitempty"`
+	FirstToSolve bool   `json:"first_to_solve"`
+}
+
+type ScoreboardRow struct {
+	Rank     int                 `json:"rank"`
+	TeamID   string              `json:"team_id"`
+	Score    ScoreboardScore     `json:"score"`
+	Problems []ScoreboardProblem `json:"problems"`
+}
+
+type Scoreboard struct {
+	Time        string          `json:"time"`
+	ContestTime string          `json:"contest_time"`
+	Rows        []ScoreboardRow `json:"rows"`
+}
+
+// Event is a line of the event feed.
+type Event struct {
+	Type string      `json:"type"`
+	ID   string      `json:"id"`
+	Op   string      `json:"op"`
+	Data interface{} `json:"data"`
+}
+
+// judgementTypes maps the verdicts to the judgement types, the verdicts missing are not final.
+// Passed pretests are correct as on the scoreboard, the system test updates the judgement.
+var judgementTypes = map[string]JudgementType{
+	common.Accept:            {ID: "AC", Name: "correct", Solved: true},
+	common.PretestsPassed:    {ID: "AC", Name: "correct", Solved: true},
+	common.WrongAnswer:       {ID: "WA", Name: "wrong answer", Penalty: true},
+	common.TimeLimit:         {ID: "TLE", Name: "time limit exceeded", Penalty: true},
+	common.MemoryLimit:       {ID: "MLE", Name: "memory limit exceeded", Penalty: true},
+	common.RuntimeError:      {ID: "RTE", Name: "run-time error", Penalty: true},
+	common.OutputLimit:       {ID: "OLE", Name: "output limit exceeded", Penalty: true},
+	common.PresentationError: {ID: "PE", Name: "presentation error", Penalty: true},
+	common.CompileError:      {ID: "CE", Name: "compiler error"},
+	common.SysteamError:      {ID: "JE", Name: "judging error"},
+	common.InternalError:     {ID: "JE", Name: "judging error"},
+}
+
+// JudgementTypes returns the judgement types in a stable order.
+func JudgementTypes() []JudgementType {
+	verdicts := []string{common.Accept, common.WrongAnswer, common.TimeLimit, common.MemoryLimit,
+		common.RuntimeError, common.OutputLimit, common.PresentationError, common.CompileError,
+		common.SysteamError}
+	types := make([]JudgementType, 0, len(verdicts))
+	for _, verdict := range verdicts {
+		types = append(types, judgementType(verdict))
+	}
+	return types
+}
+
+func judgementType(verdict string) JudgementType {
+	t := judgementTypes[verdict]
+	if t.ID == "CE" {
+		t.Penalty = common.Config.Scoreboard.PenaltyCompileError
+	}
+	return t
+}
+
+// JudgementTypeID returns the judgement type of the verdict, false if the verdict is not final.
+func JudgementTypeID(verdict string) (string, bool) {
+	t, ok := judgementTypes[verdict]
+	return t.ID, ok
+}
+
+// Time formats an absolute time.
+func Time(t time.Time) string {
+	return t.Format("2006-01-02T15:04:05.000Z07:00")
+}
+
+// RelTime formats a duration as `h:mm:ss.uuu`.
+func RelTime(d time.Duration) string {
+	sign := ""
+	if d < 0 {
+		sign, d = "-", -d
+	}
+	ms := int64(d / time.Millisecond)
+	return fmt.Sprintf("%s%d:%02d:%02d.%03d", sign, ms/3600000, ms/60000%60, ms/1000%60, ms%1000)
+}
+
+// Label returns the label of the problem at the position: A, B, ..., Z, AA, AB...
+func Label(position int) string {
+	label := ""
+	for position++; position > 0; position = (position - 1) / 26 {
+		label = string(rune('A'+(position-1)%26)) + label
+	}
+	return label
+}
+
+func NewContest(contest model.Contest, penaltyTime int) Contest {
+	c := Contest{
+		ID:             strconv.FormatInt(contest.ID, 10),
+		Name:           contest.Title,
+		FormalName:     contest.Title,
+		StartTime:      Time(contest.StartAt),
+		Duration:       RelTime(contest.EndAt.Sub(contest.StartAt)),
+		ScoreboardType: "pass-fail",
+		PenaltyTime:    penaltyTime,
+	}
+	if contest.FreezeMinutes != 0 {
+		freeze := RelTime(time.Duration(contest.FreezeMinutes) * time.Minute)
+		c.ScoreboardFreezeDuration = &freeze
+	}
+	if contest.Mode != model.ICPCMode {
+		c.ScoreboardType = "score"
+	}
+	return c
+}
+
+// NewProblem converts the problem at the position of the contest, tests is its test case count.
+func NewProblem(problem model.Problem, position, tests int) Problem {
+	return Problem{
+		ID:            strconv.FormatInt(problem.ID, 10),
+		Label:         Label(position),
+		Name:          problem.Name,
+		Ordinal:       position,
+		TimeLimit:     float64(problem.TimeLimit) / 1000,
+		TestDataCount: tests,
+	}
+}
+
+func NewSubmission(contest model.Contest, submit model.ContestSubmit) Submission {
+	return Submission{
+		ID:          submit.SubmitID,
+		LanguageID:  strings.ToLower(submit.Language),
+		ProblemID:   strconv.Itoa(submit.PID),
+		TeamID:      submit.UID,
+		Time:        Time(submit.CreatedAT),
+		ContestTime: RelTime(submit.CreatedAT.Sub(contest.StartAt)),
+	}
+}
+
+// NewJudgement returns the judgement of the submission, its end is the last update of the
+// submission once the verdict is final.
+func NewJudgement(contest model.Contest, submit model.ContestSubmit) Judgement {
+	j := Judgement{
+		ID:               submit.SubmitID,
+		SubmissionID:     submit.SubmitID,
+		StartTime:        Time(submit.CreatedAT),
+		StartContestTime: RelTime(submit.CreatedAT.Sub(contest.StartAt)),
+	}
+	if id, ok := JudgementTypeID(submit.Result); ok {
+		end, endContest := Time(submit.UpdateAT), RelTime(submit.UpdateAT.Sub(contest.StartAt))
+		j.JudgementTypeID, j.EndTime, j.EndContestTime = &id, &end, &endContest
+	}
+	return j
+}
+
+// NewScoreboard converts the ICPC standings at the time.
+func NewScoreboard(contest model.Contest, standings []scoreboard.ICPCRow, now time.Time) Scoreboard {
+	sb := Scoreboard{
+		Time:        Time(now),
+		ContestTime: RelTime(now.Sub(contest.StartAt)),
+		Rows:        make([]ScoreboardRow, 0, len(standings)),
+	}
+	for _, row := range standings {
+		r := ScoreboardRow{
+			Rank:     row.Rank,
+			TeamID:   row.UID,
+			Score:    ScoreboardScore{NumSolved: row.Solved, TotalTime: row.Penalty},
+			Problems: make([]ScoreboardProblem, 0, len(row.Cells)),
+		}
+		for _, cell := range row.Cells {
+			p := ScoreboardProblem{
+				ProblemID:    strconv.Itoa(cell.PID),
+				NumJudged:    cell.Attempts,
+				NumPending:   cell.Pending,
+				Solved:       cell.Solved,
+				FirstToSolve: cell.FirstBlood,
+			}
+			if cell.Solved {
+				solvedAt := cell.SolvedAt
+				p.Time = &solvedAt
+			}
+			r.Problems = append(r.Problems, p)
+		}
+		sb.Rows = append(sb.Rows, r)
+	}
+	return sb
+}
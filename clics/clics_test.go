@@ -0,0 +1,33 @@
+package clics
+
+import (
+	"testing"
+	"time"
+
+	"online_judge/JudgeServer/common"
+)
+
+func TestFormat(t *testing.T) {
+	for d, expect := range map[time.Duration]string{
+		5*time.Hour + 3*time.Minute + 7*time.Second + 250*time.Millisecond: "5:03:07.250",
+		-90 * time.Second: "-0:01:30.000",
+	} {
+		if got := RelTime(d); got != expect {
+			t.Errorf("expect %s, but got %s", expect, got)
+		}
+	}
+	for position, expect := range map[int]string{0: "A", 25: "Z", 26: "AA", 27: "AB"} {
+		if got := Label(position); got != expect {
+			t.Errorf("expect %s, but got %s", expect, got)
+		}
+	}
+	if id, ok := JudgementTypeID(common.TimeLimit); !ok || id != "TLE" {
+		t.Errorf("expect TLE, but got %s", id)
+	}
+	if id, ok := JudgementTypeID(common.PretestsPassed); !ok || id != "AC" {
+		t.Errorf("expect pretests passed AC as on the scoreboard, but got %s", id)
+	}
+	if _, ok := JudgementTypeID(common.Running); ok {
+		t.Errorf("expect running not final")
+	}
+}
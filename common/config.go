@@ -9,14 +9,19 @@ import (
 var Config Configs
 
 type Configs struct {
-	Listen    int
-	AllowCORS bool
-	MySQL     db.MySQLConfig
-	Redis     db.RedisConfig
-	Compile   CompileConfig
-	SandBox   SandBoxConfig
-	Token     TokenConfig
-	Static    StaticConfig
+	Listen     int
+	AllowCORS  bool
+	Admins     []string
+	MySQL      db.MySQLConfig
+	Redis      db.RedisConfig
+	Compile    CompileConfig
+	SandBox    SandBoxConfig
+	Token      TokenConfig
+	Static     StaticConfig
+	Prepare    PrepareConfig
+	Output     OutputConfig
+	Judge      JudgeConfig
+	Scoreboard ScoreboardConfig
 }
 
 type CompileConfig struct {
@@ -28,6 +33,36 @@ type SandBoxConfig struct {
 	Exe        string
 	ProblemDir string
 	OutPutDir  string
+	// the sqlite3 shell running the queries of SQL problems, looked up in PATH if empty.
+	SQLite string
+}
+
+type JudgeConfig struct {
+	// number of submissions judged at the same time, defaults to the number of CPUs.
+	Workers int
+	// bytes a program may write to stdout or any file, defaults to 64MB.
+	MaxOutputSize int64
+	// the programs run with RunUID and RunGID if RunUID is set, the problem data directory should
+	// not be readable by them.
+	RunUID int
+	RunGID int
+}
+
+type ScoreboardConfig struct {
+	// penalty minutes of a rejected attempt before the accepted one, defaults to 20.
+	PenaltyMinutes int
+	// compile errors are not penalized unless set.
+	PenaltyCompileError bool
+}
+
+// OutputConfig controls the output kept for failed test cases.
+type OutputConfig struct {
+	// bytes of stdout and stderr kept per test case.
+	MaxSize int
+	// kept outputs older than Retention are removed.
+	Retention Duration
+	// the oldest outputs are removed when the total size exceeds TotalSize bytes.
+	TotalSize int64
 }
 
 type TokenConfig struct {
@@ -38,6 +73,13 @@ type StaticConfig struct {
 	ImagePath string
 }
 
+type PrepareConfig struct {
+	// suggested time limit = slowest correct solution * TimeLimitFactor.
+	TimeLimitFactor float64
+	// a test is tight when the reference solution uses more than (1 - TimeLimitMargin) of the limit.
+	TimeLimitMargin float64
+}
+
 func InitConfig(fpath string) {
 	config, err := loadConfig(fpath)
 	if err != nil {
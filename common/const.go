@@ -4,17 +4,31 @@ const (
 	CLanguage   = "C"
 	CPPLanguage = "CPP"
 	GoLanguage  = "Golang"
+	// JavaLanguage code should declare a public class Main.
+	JavaLanguage   = "Java"
+	PythonLanguage = "Python"
+	// OutputLanguage marks the submissions of output-only problems.
+	OutputLanguage = "Output"
+	// SQLLanguage queries are judged by sandbox.SQLJudge.
+	SQLLanguage = "SQL"
 
 	Accept            = "Accepted"
 	CompileError      = "Compile Error"
 	Running           = "Running"
+	Pending           = "Pending"
+	Compiling         = "Compiling"
 	WrongAnswer       = "Wrong Answer"
 	TimeLimit         = "Time Limit"
 	MemoryLimit       = "Memory Limit"
 	RuntimeError      = "Runtime Error"
 	SysteamError      = "System Error"
 	PresentationError = "Presentation Error"
+	OutputLimit       = "Output Limit"
 	InternalError     = "internal Error"
+	PretestsPassed    = "Pretests Passed"
+	Skipped           = "Skipped"
+	// Submitted is shown instead of the result while it is hidden.
+	Submitted = "Submitted"
 
 	// token header
 	AuthHeader = "Authorization"
@@ -2,7 +2,6 @@ package compile
 
 import (
 	"bytes"
-	"fmt"
 	"os/exec"
 
 	"github.com/pkg/errors"
@@ -12,9 +11,14 @@ type CCompile struct {
 }
 
 func (c *CCompile) Compile(codeFile, exeFile string) (string, error) {
+	return c.CompileFiles([]string{codeFile}, "", exeFile)
+}
+
+func (c *CCompile) CompileFiles(codeFiles []string, includeDir, exeFile string) (string, error) {
 	var stderr bytes.Buffer
-	cmd := exec.Command("bash", "-c",
-		fmt.Sprintf("gcc  -DONLINE_JUDGE  -O2  -w -fmax-errors=3  -std=c11 %s -lm -o %s", codeFile, exeFile))
+	args := append([]string{"-DONLINE_JUDGE", "-O2", "-w", "-fmax-errors=3", "-std=c11"}, includeFlag(includeDir)...)
+	args = append(append(args, codeFiles...), "-lm", "-o", exeFile)
+	cmd := exec.Command("gcc", args...)
 	cmd.Stderr = &stderr
 	err := cmd.Run()
 	if err != nil {
@@ -13,13 +13,59 @@ type Compiler interface {
 	Compile(codeFile, exeFile string) (string, error)
 }
 
+// LinkCompiler compiles several source files into one executable, such as a submission together
+// with the grader of the problem. Headers are searched in includeDir.
+type LinkCompiler interface {
+	CompileFiles(codeFiles []string, includeDir, exeFile string) (string, error)
+}
+
+// seccomp rules of the sandbox.
+const (
+	SeccompCCpp    = "c_cpp"
+	SeccompGeneral = "general"
+	// SeccompGolang is the c_cpp rule allowing the threads of the go runtime.
+	SeccompGolang = "golang"
+	// SeccompCCppFileIO is the c_cpp rule allowing to write files, used by file I/O problems.
+	SeccompCCppFileIO = "c_cpp_file_io"
+	// SeccompGeneralFileIO is the general rule allowing to create files, built with the sandbox.
+	SeccompGeneralFileIO = "general_file_io"
+	// SeccompNone runs the program without seccomp rule.
+	SeccompNone = "none"
+)
+
+// Runner is implemented by the compilers of languages that need an interpreter or a runtime
+// incompatible with the c_cpp seccomp rule. It returns the program, the arguments and the
+// seccomp rule used to run the compiled file.
+type Runner interface {
+	RunCommand(exeFile string) (string, []string, string)
+}
+
+// HeapLimiter is implemented by the runtimes reserving more address space than they use, such as
+// the JVM. The sandbox then only checks the memory used, the runtime enforces the limit given by
+// the returned arguments.
+type HeapLimiter interface {
+	HeapArgs(memoryLimit int64) []string
+}
+
+func includeFlag(includeDir string) []string {
+	if includeDir == "" {
+		return nil
+	}
+	return []string{"-I", includeDir}
+}
+
 func NewCompile(language string) (Compiler, error) {
 	switch strings.ToUpper(language) {
 	case common.CLanguage:
 		return &CCompile{}, nil
 	case common.CPPLanguage:
 		return &CPPCompile{}, nil
-
+	case strings.ToUpper(common.GoLanguage):
+		return &GoCompile{}, nil
+	case strings.ToUpper(common.JavaLanguage):
+		return &JavaCompile{}, nil
+	case strings.ToUpper(common.PythonLanguage):
+		return &PythonCompile{}, nil
 	}
 	return nil, errors.New(fmt.Sprintf("%s not found.", language))
 }
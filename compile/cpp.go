@@ -2,7 +2,6 @@ package compile
 
 import (
 	"bytes"
-	"fmt"
 	"os/exec"
 
 	"github.com/pkg/errors"
@@ -11,10 +10,15 @@ import (
 type CPPCompile struct {
 }
 
-func (CPPCompile) Compile(codeFile, exePath string) (string, error) {
+func (c CPPCompile) Compile(codeFile, exePath string) (string, error) {
+	return c.CompileFiles([]string{codeFile}, "", exePath)
+}
+
+func (CPPCompile) CompileFiles(codeFiles []string, includeDir, exePath string) (string, error) {
 	var stderr bytes.Buffer
-	cmd := exec.Command("bash", "-c",
-		fmt.Sprintf("g++ -DONLINE_JUDGE -O2 -w -fmax-errors=3 -std=c++11 %s -lm -o %s", codeFile, exePath))
+	args := append([]string{"-DONLINE_JUDGE", "-O2", "-w", "-fmax-errors=3", "-std=c++11"}, includeFlag(includeDir)...)
+	args = append(append(args, codeFiles...), "-lm", "-o", exePath)
+	cmd := exec.Command("g++", args...)
 	cmd.Stderr = &stderr
 	if err := cmd.Run(); err != nil {
 		return "", errors.WithMessage(err, stderr.String())
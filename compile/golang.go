@@ -0,0 +1,27 @@
+package compile
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+
+	"github.com/pkg/errors"
+)
+
+type GoCompile struct {
+}
+
+func (GoCompile) Compile(codeFile, exeFile string) (string, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("bash", "-c", fmt.Sprintf("go build -o %s %s", exeFile, codeFile))
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", errors.WithMessage(err, stderr.String())
+	}
+	return exeFile, nil
+}
+
+// RunCommand runs the executable with the golang seccomp rule, the go runtime needs threads.
+func (GoCompile) RunCommand(exeFile string) (string, []string, string) {
+	return exeFile, nil, SeccompGolang
+}
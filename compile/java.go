@@ -0,0 +1,46 @@
+package compile
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// JavaCompile compiles the code as Main.java, the exeFile is the directory of the classes.
+type JavaCompile struct {
+}
+
+func (JavaCompile) Compile(codeFile, exeFile string) (string, error) {
+	code, err := ioutil.ReadFile(codeFile)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	if err := os.MkdirAll(exeFile, os.ModePerm); err != nil {
+		return "", errors.WithStack(err)
+	}
+	mainFile := filepath.Join(exeFile, "Main.java")
+	if err := ioutil.WriteFile(mainFile, code, os.ModePerm); err != nil {
+		return "", errors.WithStack(err)
+	}
+	var stderr bytes.Buffer
+	cmd := exec.Command("bash", "-c", fmt.Sprintf("javac -encoding UTF-8 -d %s %s", exeFile, mainFile))
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", errors.WithMessage(err, stderr.String())
+	}
+	return exeFile, nil
+}
+
+func (JavaCompile) RunCommand(exeFile string) (string, []string, string) {
+	return "/usr/bin/java", []string{"-cp", exeFile, "Main"}, SeccompGeneral
+}
+
+// HeapArgs limits the heap of the JVM to the memory limit.
+func (JavaCompile) HeapArgs(memoryLimit int64) []string {
+	return []string{fmt.Sprintf("-Xmx%dk", memoryLimit/1024), "-XX:+UseSerialGC"}
+}
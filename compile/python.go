@@ -0,0 +1,37 @@
+package compile
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+
+	"github.com/pkg/errors"
+)
+
+// PythonCompile only checks the syntax, the exeFile is a copy of the code.
+type PythonCompile struct {
+}
+
+func (PythonCompile) Compile(codeFile, exeFile string) (string, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("bash", "-c", fmt.Sprintf("python3 -m py_compile %s", codeFile))
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", errors.WithMessage(err, stderr.String())
+	}
+	code, err := ioutil.ReadFile(codeFile)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	exeFile += ".py"
+	if err := ioutil.WriteFile(exeFile, code, os.ModePerm); err != nil {
+		return "", errors.WithStack(err)
+	}
+	return exeFile, nil
+}
+
+func (PythonCompile) RunCommand(exeFile string) (string, []string, string) {
+	return "/usr/bin/python3", []string{exeFile}, SeccompGeneral
+}
@@ -0,0 +1,99 @@
+package driver
+
+import (
+	"strings"
+)
+
+const cPrelude = `#include <stdio.h>
+#include <stdlib.h>
+#include <string.h>
+#include <stdbool.h>
+
+static int oj_read_int(void) { int v = 0; scanf("%d", &v); return v; }
+static long long oj_read_long(void) { long long v = 0; scanf("%lld", &v); return v; }
+static double oj_read_double(void) { double v = 0; scanf("%lf", &v); return v; }
+static bool oj_read_bool(void) { char t[8] = {0}; scanf("%7s", t); return strcmp(t, "true") == 0; }
+static char *oj_read_string(void) {
+	int n = oj_read_int();
+	char *s = (char *)malloc(n + 1);
+	getchar();
+	s[fread(s, 1, n, stdin)] = 0;
+	return s;
+}
+static void oj_write_int(int v) { printf("%d\n", v); }
+static void oj_write_long(long long v) { printf("%lld\n", v); }
+static void oj_write_double(double v) { printf("%.5f\n", v); }
+static void oj_write_bool(bool v) { printf("%s\n", v ? "true" : "false"); }
+static void oj_write_string(const char *v) { printf("%d\n%s\n", (int)strlen(v), v); }
+`
+
+type cDriver struct{}
+
+func (cDriver) typ(base string) string {
+	switch base {
+	case Long:
+		return "long long"
+	case String:
+		return "char*"
+	}
+	return base
+}
+
+// params declares the parameters following the LeetCode C convention: arrays are passed with
+// their size, returned arrays report their size through returnSize.
+func (d cDriver) params(sig *Signature) string {
+	params := make([]string, 0, len(sig.Params)+1)
+	for _, p := range sig.Params {
+		if p.Type.Array {
+			params = append(params, d.typ(p.Type.Base)+"* "+p.Name, "int "+p.Name+"Size")
+		} else {
+			params = append(params, d.typ(p.Type.Base)+" "+p.Name)
+		}
+	}
+	if sig.Return.Array {
+		params = append(params, "int* returnSize")
+	}
+	return strings.Join(params, ", ")
+}
+
+func (d cDriver) starter(sig *Signature) string {
+	ret := d.typ(sig.Return.Base)
+	if sig.Return.Array {
+		ret += "*"
+	}
+	return ret + " " + sig.Name + "(" + d.params(sig) + ") {\n\n}\n"
+}
+
+func (d cDriver) generate(sig *Signature, code string) string {
+	var b builder
+	b.line(cPrelude)
+	b.line(code)
+	b.line("int main(void) {")
+	args := make([]string, 0, len(sig.Params)+1)
+	for _, p := range sig.Params {
+		typ, read := d.typ(p.Type.Base), "oj_read_"+p.Type.Base+"()"
+		if !p.Type.Array {
+			b.line("\t", typ, " ", p.Name, " = ", read, ";")
+			args = append(args, p.Name)
+			continue
+		}
+		size := p.Name + "Size"
+		b.line("\tint ", size, " = oj_read_int();")
+		b.line("\t", typ, "* ", p.Name, " = (", typ, "*)malloc(sizeof(", typ, ") * (", size, " + 1));")
+		b.line("\tfor (int oj_i = 0; oj_i < ", size, "; oj_i++) ", p.Name, "[oj_i] = ", read, ";")
+		args = append(args, p.Name, size)
+	}
+	write := "oj_write_" + sig.Return.Base
+	if !sig.Return.Array {
+		b.line("\t", write, "(", sig.Name, "(", strings.Join(args, ", "), "));")
+	} else {
+		args = append(args, "&oj_size")
+		b.line("\tint oj_size = 0;")
+		b.line("\t", d.typ(sig.Return.Base), "* oj_ret = ", sig.Name, "(", strings.Join(args, ", "), ");")
+		b.line("\toj_write_int(oj_size);")
+		b.line("\tfor (int oj_i = 0; oj_i < oj_size; oj_i++) ", write, "(oj_ret[oj_i]);")
+	}
+	b.line("\treturn 0;")
+	b.line("}")
+	return b.String()
+}
@@ -0,0 +1,79 @@
+package driver
+
+import (
+	"strings"
+)
+
+const cppPrelude = `#include <bits/stdc++.h>
+using namespace std;
+
+static int oj_read_int() { int v = 0; cin >> v; return v; }
+static long long oj_read_long() { long long v = 0; cin >> v; return v; }
+static double oj_read_double() { double v = 0; cin >> v; return v; }
+static bool oj_read_bool() { string t; cin >> t; return t == "true"; }
+static string oj_read_string() {
+	int n = oj_read_int();
+	string s(n, '\0');
+	cin.get();
+	cin.read(&s[0], n);
+	return s;
+}
+static void oj_write(int v) { cout << v << "\n"; }
+static void oj_write(long long v) { cout << v << "\n"; }
+static void oj_write(double v) { cout << fixed << setprecision(5) << v << "\n"; }
+static void oj_write(bool v) { cout << (v ? "true" : "false") << "\n"; }
+static void oj_write(const string &v) { cout << v.size() << "\n" << v << "\n"; }
+template <typename T> static void oj_write(const vector<T> &v) {
+	cout << v.size() << "\n";
+	for (size_t i = 0; i < v.size(); i++) oj_write((T)v[i]);
+}
+`
+
+type cppDriver struct{}
+
+func (cppDriver) typ(t Type) string {
+	base := t.Base
+	if base == Long {
+		base = "long long"
+	}
+	if t.Array {
+		return "vector<" + base + ">"
+	}
+	return base
+}
+
+func (d cppDriver) starter(sig *Signature) string {
+	params := make([]string, 0, len(sig.Params))
+	for _, p := range sig.Params {
+		if p.Type.Array || p.Type.Base == String {
+			params = append(params, d.typ(p.Type)+"& "+p.Name)
+		} else {
+			params = append(params, d.typ(p.Type)+" "+p.Name)
+		}
+	}
+	return "class Solution {\npublic:\n    " + d.typ(sig.Return) + " " + sig.Name + "(" +
+		strings.Join(params, ", ") + ") {\n\n    }\n};\n"
+}
+
+func (d cppDriver) generate(sig *Signature, code string) string {
+	var b builder
+	b.line(cppPrelude)
+	b.line(code)
+	b.line("int main() {")
+	b.line("\tios::sync_with_stdio(false);")
+	args := make([]string, 0, len(sig.Params))
+	for _, p := range sig.Params {
+		read := "oj_read_" + p.Type.Base + "()"
+		if p.Type.Array {
+			b.line("\t", d.typ(p.Type), " ", p.Name, "(oj_read_int());")
+			b.line("\tfor (size_t oj_i = 0; oj_i < ", p.Name, ".size(); oj_i++) ", p.Name, "[oj_i] = ", read, ";")
+		} else {
+			b.line("\t", d.typ(p.Type), " ", p.Name, " = ", read, ";")
+		}
+		args = append(args, p.Name)
+	}
+	b.line("\toj_write(Solution().", sig.Name, "(", strings.Join(args, ", "), "));")
+	b.line("\treturn 0;")
+	b.line("}")
+	return b.String()
+}
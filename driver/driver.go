@@ -0,0 +1,61 @@
+package driver
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"online_judge/JudgeServer/common"
+)
+
+// language generates the starter code and the I/O driver of one language.
+type language interface {
+	starter(sig *Signature) string
+	generate(sig *Signature, code string) string
+}
+
+var languages = map[string]language{
+	common.CLanguage:      cDriver{},
+	common.CPPLanguage:    cppDriver{},
+	common.GoLanguage:     goDriver{},
+	common.JavaLanguage:   javaDriver{},
+	common.PythonLanguage: pythonDriver{},
+}
+
+var packageRegexp = regexp.MustCompile(`(?m)^\s*package\s+\w+\s*;?\s*$`)
+
+// Generate merges the user code with the driver that reads the arguments, calls the function and
+// writes the returned value.
+func Generate(sig *Signature, lang, code string) (string, error) {
+	l, ok := languages[lang]
+	if !ok {
+		return "", errors.Errorf("%s not support.", lang)
+	}
+	return l.generate(sig, code), nil
+}
+
+// Starters returns the starter code template of every supported language.
+func Starters(sig *Signature) map[string]string {
+	starters := make(map[string]string, len(languages))
+	for lang, l := range languages {
+		starters[lang] = l.starter(sig)
+	}
+	return starters
+}
+
+// title returns the base type with the first letter upper cased, used to name the reader and
+// writer functions of the drivers.
+func title(base string) string {
+	return strings.ToUpper(base[:1]) + base[1:]
+}
+
+// builder writes the generated code line by line.
+type builder struct {
+	strings.Builder
+}
+
+func (b *builder) line(s ...string) {
+	b.WriteString(strings.Join(s, ""))
+	b.WriteString("\n")
+}
@@ -0,0 +1,100 @@
+package driver
+
+import (
+	"strings"
+)
+
+const goImports = `package main
+
+import (
+	ojbufio "bufio"
+	ojfmt "fmt"
+	ojio "io"
+	ojos "os"
+)
+`
+
+const goHelpers = `var (
+	ojReader = ojbufio.NewReader(ojos.Stdin)
+	ojWriter = ojbufio.NewWriter(ojos.Stdout)
+)
+
+func ojReadInt() int { var v int; ojfmt.Fscan(ojReader, &v); return v }
+func ojReadLong() int64 { var v int64; ojfmt.Fscan(ojReader, &v); return v }
+func ojReadDouble() float64 { var v float64; ojfmt.Fscan(ojReader, &v); return v }
+func ojReadBool() bool { var v string; ojfmt.Fscan(ojReader, &v); return v == "true" }
+func ojReadString() string {
+	b := make([]byte, ojReadInt())
+	ojReader.ReadByte()
+	ojio.ReadFull(ojReader, b)
+	return string(b)
+}
+func ojWriteInt(v int) { ojfmt.Fprintf(ojWriter, "%d\n", v) }
+func ojWriteLong(v int64) { ojfmt.Fprintf(ojWriter, "%d\n", v) }
+func ojWriteDouble(v float64) { ojfmt.Fprintf(ojWriter, "%.5f\n", v) }
+func ojWriteBool(v bool) { ojfmt.Fprintf(ojWriter, "%t\n", v) }
+func ojWriteString(v string) { ojfmt.Fprintf(ojWriter, "%d\n%s\n", len(v), v) }
+`
+
+type goDriver struct{}
+
+func (goDriver) typ(t Type) string {
+	var base string
+	switch t.Base {
+	case Long:
+		base = "int64"
+	case Double:
+		base = "float64"
+	default:
+		base = t.Base
+	}
+	if t.Array {
+		return "[]" + base
+	}
+	return base
+}
+
+func (d goDriver) starter(sig *Signature) string {
+	params := make([]string, 0, len(sig.Params))
+	for _, p := range sig.Params {
+		params = append(params, p.Name+" "+d.typ(p.Type))
+	}
+	return "func " + sig.Name + "(" + strings.Join(params, ", ") + ") " + d.typ(sig.Return) + " {\n\n}\n"
+}
+
+// generate puts the user code between the driver imports and the driver functions, so the
+// imports of the user code stay in front of the declarations.
+func (d goDriver) generate(sig *Signature, code string) string {
+	var b builder
+	b.line(goImports)
+	b.line(packageRegexp.ReplaceAllString(code, ""))
+	b.line(goHelpers)
+	b.line("func main() {")
+	b.line("\tdefer ojWriter.Flush()")
+	args := make([]string, 0, len(sig.Params))
+	for _, p := range sig.Params {
+		read := "ojRead" + title(p.Type.Base) + "()"
+		if p.Type.Array {
+			b.line("\t", p.Name, " := make(", d.typ(p.Type), ", ojReadInt())")
+			b.line("\tfor ojI := range ", p.Name, " {")
+			b.line("\t\t", p.Name, "[ojI] = ", read)
+			b.line("\t}")
+		} else {
+			b.line("\t", p.Name, " := ", read)
+		}
+		args = append(args, p.Name)
+	}
+	write := "ojWrite" + title(sig.Return.Base)
+	call := sig.Name + "(" + strings.Join(args, ", ") + ")"
+	if sig.Return.Array {
+		b.line("\tojRet := ", call)
+		b.line("\tojWriteInt(len(ojRet))")
+		b.line("\tfor _, ojV := range ojRet {")
+		b.line("\t\t", write, "(ojV)")
+		b.line("\t}")
+	} else {
+		b.line("\t", write, "(", call, ")")
+	}
+	b.line("}")
+	return b.String()
+}
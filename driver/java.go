@@ -0,0 +1,98 @@
+package driver
+
+import (
+	"strings"
+)
+
+const javaImports = `import java.io.*;
+import java.util.*;
+`
+
+const javaHelpers = `    static DataInputStream ojIn = new DataInputStream(new BufferedInputStream(System.in));
+    static StringBuilder ojOut = new StringBuilder();
+
+    static boolean ojSpace(int c) { return c == ' ' || c == '\n' || c == '\r' || c == '\t'; }
+    static String ojToken() throws IOException {
+        int c = ojIn.read();
+        while (ojSpace(c)) c = ojIn.read();
+        StringBuilder sb = new StringBuilder();
+        while (c != -1 && !ojSpace(c)) { sb.append((char) c); c = ojIn.read(); }
+        return sb.toString();
+    }
+    static int ojReadInt() throws IOException { return Integer.parseInt(ojToken()); }
+    static long ojReadLong() throws IOException { return Long.parseLong(ojToken()); }
+    static double ojReadDouble() throws IOException { return Double.parseDouble(ojToken()); }
+    static boolean ojReadBool() throws IOException { return ojToken().equals("true"); }
+    static String ojReadString() throws IOException {
+        byte[] b = new byte[ojReadInt()];
+        ojIn.readFully(b);
+        return new String(b, "UTF-8");
+    }
+    static void ojWrite(int v) { ojOut.append(v).append('\n'); }
+    static void ojWrite(long v) { ojOut.append(v).append('\n'); }
+    static void ojWrite(double v) { ojOut.append(String.format(Locale.ROOT, "%.5f", v)).append('\n'); }
+    static void ojWrite(boolean v) { ojOut.append(v).append('\n'); }
+    static void ojWrite(String v) throws IOException {
+        ojOut.append(v.getBytes("UTF-8").length).append('\n').append(v).append('\n');
+    }
+    static void ojWrite(int[] v) { ojWrite(v.length); for (int x : v) ojWrite(x); }
+    static void ojWrite(long[] v) { ojWrite(v.length); for (long x : v) ojWrite(x); }
+    static void ojWrite(double[] v) { ojWrite(v.length); for (double x : v) ojWrite(x); }
+    static void ojWrite(boolean[] v) { ojWrite(v.length); for (boolean x : v) ojWrite(x); }
+    static void ojWrite(String[] v) throws IOException { ojWrite(v.length); for (String x : v) ojWrite(x); }
+`
+
+type javaDriver struct{}
+
+func (javaDriver) typ(t Type) string {
+	var base string
+	switch t.Base {
+	case Bool:
+		base = "boolean"
+	case String:
+		base = "String"
+	default:
+		base = t.Base
+	}
+	if t.Array {
+		return base + "[]"
+	}
+	return base
+}
+
+func (d javaDriver) starter(sig *Signature) string {
+	params := make([]string, 0, len(sig.Params))
+	for _, p := range sig.Params {
+		params = append(params, d.typ(p.Type)+" "+p.Name)
+	}
+	return "class Solution {\n    public " + d.typ(sig.Return) + " " + sig.Name + "(" +
+		strings.Join(params, ", ") + ") {\n\n    }\n}\n"
+}
+
+// generate puts the driver imports in front of the user code, the Main class follows it.
+func (d javaDriver) generate(sig *Signature, code string) string {
+	var b builder
+	b.line(javaImports)
+	b.line(code)
+	b.line("public class Main {")
+	b.line(javaHelpers)
+	b.line("    public static void main(String[] args) throws Exception {")
+	args := make([]string, 0, len(sig.Params))
+	for _, p := range sig.Params {
+		read := "ojRead" + title(p.Type.Base) + "()"
+		if p.Type.Array {
+			b.line("        ", d.typ(p.Type), " ", p.Name, " = new ", d.typ(Type{Base: p.Type.Base}), "[ojReadInt()];")
+			b.line("        for (int ojI = 0; ojI < ", p.Name, ".length; ojI++) ", p.Name, "[ojI] = ", read, ";")
+		} else {
+			b.line("        ", d.typ(p.Type), " ", p.Name, " = ", read, ";")
+		}
+		args = append(args, p.Name)
+	}
+	b.line("        ojWrite(new Solution().", sig.Name, "(", strings.Join(args, ", "), "));")
+	b.line("        PrintStream out = new PrintStream(System.out, false, \"UTF-8\");")
+	b.line("        out.print(ojOut);")
+	b.line("        out.flush();")
+	b.line("    }")
+	b.line("}")
+	return b.String()
+}
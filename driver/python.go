@@ -0,0 +1,128 @@
+package driver
+
+import (
+	"strings"
+)
+
+const pythonImports = `import sys
+from typing import List
+`
+
+const pythonHelpers = `_oj_data = sys.stdin.buffer.read()
+_oj_pos = 0
+_oj_out = []
+
+
+def _oj_token():
+    global _oj_pos
+    n = len(_oj_data)
+    while _oj_pos < n and _oj_data[_oj_pos] in b' \t\r\n':
+        _oj_pos += 1
+    start = _oj_pos
+    while _oj_pos < n and _oj_data[_oj_pos] not in b' \t\r\n':
+        _oj_pos += 1
+    token = _oj_data[start:_oj_pos]
+    _oj_pos += 1
+    return token.decode()
+
+
+def _oj_read_int():
+    return int(_oj_token())
+
+
+_oj_read_long = _oj_read_int
+
+
+def _oj_read_double():
+    return float(_oj_token())
+
+
+def _oj_read_bool():
+    return _oj_token() == 'true'
+
+
+def _oj_read_string():
+    global _oj_pos
+    n = _oj_read_int()
+    s = _oj_data[_oj_pos:_oj_pos + n]
+    _oj_pos += n
+    return s.decode('utf-8')
+
+
+def _oj_write_int(v):
+    _oj_out.append('%d\n' % v)
+
+
+_oj_write_long = _oj_write_int
+
+
+def _oj_write_double(v):
+    _oj_out.append('%.5f\n' % v)
+
+
+def _oj_write_bool(v):
+    _oj_out.append('true\n' if v else 'false\n')
+
+
+def _oj_write_string(v):
+    _oj_out.append('%d\n%s\n' % (len(v.encode('utf-8')), v))
+`
+
+type pythonDriver struct{}
+
+func (pythonDriver) typ(t Type) string {
+	var base string
+	switch t.Base {
+	case Long:
+		base = "int"
+	case Double:
+		base = "float"
+	case String:
+		base = "str"
+	default:
+		base = t.Base
+	}
+	if t.Array {
+		return "List[" + base + "]"
+	}
+	return base
+}
+
+func (d pythonDriver) starter(sig *Signature) string {
+	params := []string{"self"}
+	for _, p := range sig.Params {
+		params = append(params, p.Name+": "+d.typ(p.Type))
+	}
+	return "class Solution:\n    def " + sig.Name + "(" + strings.Join(params, ", ") + ") -> " +
+		d.typ(sig.Return) + ":\n        pass\n"
+}
+
+func (d pythonDriver) generate(sig *Signature, code string) string {
+	var b builder
+	b.line(pythonImports)
+	b.line(code)
+	b.line()
+	b.line(pythonHelpers)
+	args := make([]string, 0, len(sig.Params))
+	for _, p := range sig.Params {
+		read := "_oj_read_" + p.Type.Base + "()"
+		if p.Type.Array {
+			b.line(p.Name, " = [", read, " for _ in range(_oj_read_int())]")
+		} else {
+			b.line(p.Name, " = ", read)
+		}
+		args = append(args, p.Name)
+	}
+	write := "_oj_write_" + sig.Return.Base
+	call := "Solution()." + sig.Name + "(" + strings.Join(args, ", ") + ")"
+	if sig.Return.Array {
+		b.line("_oj_ret = ", call)
+		b.line("_oj_write_int(len(_oj_ret))")
+		b.line("for _oj_v in _oj_ret:")
+		b.line("    ", write, "(_oj_v)")
+	} else {
+		b.line(write, "(", call, ")")
+	}
+	b.line("sys.stdout.buffer.write(''.join(_oj_out).encode('utf-8'))")
+	return b.String()
+}
@@ -0,0 +1,202 @@
+package driver
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// base types of a signature, every base type can also be used as a one dimension array: int[].
+const (
+	Int    = "int"
+	Long   = "long"
+	Double = "double"
+	Bool   = "bool"
+	String = "string"
+)
+
+var (
+	signatureRegexp = regexp.MustCompile(`^\s*(\w+(?:\[\])?)\s+([A-Za-z_]\w*)\s*\((.*)\)\s*$`)
+	paramRegexp     = regexp.MustCompile(`^\s*(\w+(?:\[\])?)\s+([A-Za-z_]\w*)\s*$`)
+)
+
+type Type struct {
+	Base  string
+	Array bool
+}
+
+func (t Type) String() string {
+	if t.Array {
+		return t.Base + "[]"
+	}
+	return t.Base
+}
+
+func parseType(s string) (Type, error) {
+	t := Type{Base: strings.TrimSuffix(s, "[]"), Array: strings.HasSuffix(s, "[]")}
+	switch t.Base {
+	case Int, Long, Double, Bool, String:
+		return t, nil
+	}
+	return t, errors.Errorf("unsupported type %s", s)
+}
+
+type Param struct {
+	Type Type
+	Name string
+}
+
+// Signature is a language neutral function signature, such as `int[] twoSum(int[] nums, int target)`.
+type Signature struct {
+	Return Type
+	Name   string
+	Params []Param
+}
+
+func (sig *Signature) String() string {
+	params := make([]string, 0, len(sig.Params))
+	for _, p := range sig.Params {
+		params = append(params, p.Type.String()+" "+p.Name)
+	}
+	return fmt.Sprintf("%s %s(%s)", sig.Return, sig.Name, strings.Join(params, ", "))
+}
+
+func ParseSignature(s string) (*Signature, error) {
+	match := signatureRegexp.FindStringSubmatch(s)
+	if match == nil {
+		return nil, errors.Errorf("invalid signature %s", s)
+	}
+	ret, err := parseType(match[1])
+	if err != nil {
+		return nil, err
+	}
+	sig := &Signature{
+		Return: ret,
+		Name:   match[2],
+		Params: make([]Param, 0),
+	}
+	if strings.TrimSpace(match[3]) == "" {
+		return sig, nil
+	}
+	names := make(map[string]bool)
+	for _, p := range strings.Split(match[3], ",") {
+		pm := paramRegexp.FindStringSubmatch(p)
+		if pm == nil {
+			return nil, errors.Errorf("invalid parameter %s", p)
+		}
+		t, err := parseType(pm[1])
+		if err != nil {
+			return nil, err
+		}
+		if names[pm[2]] || strings.HasPrefix(pm[2], "oj") {
+			return nil, errors.Errorf("invalid parameter name %s", pm[2])
+		}
+		names[pm[2]] = true
+		sig.Params = append(sig.Params, Param{Type: t, Name: pm[2]})
+	}
+	return sig, nil
+}
+
+// Case is a typed test case, Input holds one JSON value per parameter.
+type Case struct {
+	Input  []json.RawMessage `json:"input"`
+	Output json.RawMessage   `json:"output"`
+}
+
+// EncodeCase converts the JSON test case into the plain text input and output read and written
+// by the generated drivers.
+func (sig *Signature) EncodeCase(c Case) (string, string, error) {
+	if len(c.Input) != len(sig.Params) {
+		return "", "", errors.Errorf("expect %d arguments, but got %d", len(sig.Params), len(c.Input))
+	}
+	var input bytes.Buffer
+	for i, p := range sig.Params {
+		data, err := encode(p.Type, c.Input[i], false)
+		if err != nil {
+			return "", "", errors.WithMessage(err, p.Name)
+		}
+		input.WriteString(data)
+	}
+	output, err := encode(sig.Return, c.Output, true)
+	if err != nil {
+		return "", "", errors.WithMessage(err, "output")
+	}
+	return input.String(), output, nil
+}
+
+// encode writes a value in the driver format: one scalar per line, strings as their byte length
+// followed by the raw bytes, arrays as their length followed by the elements. Doubles are written
+// exactly in inputs, and with 5 decimals in outputs as the drivers print them.
+func encode(t Type, raw json.RawMessage, output bool) (string, error) {
+	if !t.Array {
+		return encodeScalar(t.Base, raw, output)
+	}
+	var list []json.RawMessage
+	if err := json.Unmarshal(raw, &list); err != nil {
+		return "", errors.Errorf("expect %s, but got %s", t, raw)
+	}
+	var buf bytes.Buffer
+	buf.WriteString(strconv.Itoa(len(list)) + "\n")
+	for _, item := range list {
+		data, err := encodeScalar(t.Base, item, output)
+		if err != nil {
+			return "", err
+		}
+		buf.WriteString(data)
+	}
+	return buf.String(), nil
+}
+
+func encodeScalar(base string, raw json.RawMessage, output bool) (string, error) {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var v interface{}
+	if err := decoder.Decode(&v); err != nil {
+		return "", errors.WithStack(err)
+	}
+	mismatch := errors.Errorf("expect %s, but got %s", base, raw)
+	switch base {
+	case Int, Long:
+		n, ok := v.(json.Number)
+		if !ok {
+			return "", mismatch
+		}
+		i, err := n.Int64()
+		if err != nil || (base == Int && (i > math.MaxInt32 || i < math.MinInt32)) {
+			return "", mismatch
+		}
+		return strconv.FormatInt(i, 10) + "\n", nil
+	case Double:
+		n, ok := v.(json.Number)
+		if !ok {
+			return "", mismatch
+		}
+		f, err := n.Float64()
+		if err != nil {
+			return "", mismatch
+		}
+		if output {
+			return fmt.Sprintf("%.5f\n", f), nil
+		}
+		return strconv.FormatFloat(f, 'g', -1, 64) + "\n", nil
+	case Bool:
+		b, ok := v.(bool)
+		if !ok {
+			return "", mismatch
+		}
+		return strconv.FormatBool(b) + "\n", nil
+	case String:
+		s, ok := v.(string)
+		if !ok {
+			return "", mismatch
+		}
+		return fmt.Sprintf("%d\n%s\n", len(s), s), nil
+	}
+	return "", mismatch
+}
@@ -0,0 +1,63 @@
+package driver
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseSignature(t *testing.T) {
+	for s, valid := range map[string]bool{
+		"int[] twoSum(int[] nums, int target)":      true,
+		"bool isEmpty()":                            true,
+		"string join( string[] words ,string sep )": true,
+		"int[][] grid()":                            false,
+		"char first(string s)":                      false,
+		"int f(int a, int a)":                       false,
+		"int f(int ojValue)":                        false,
+		"int f(int a,)":                             false,
+		"twoSum(int[] nums)":                        false,
+	} {
+		if _, err := ParseSignature(s); (err == nil) != valid {
+			t.Errorf("%q: expect valid %v, but got %v", s, valid, err)
+		}
+	}
+}
+
+func TestEncodeCase(t *testing.T) {
+	sig, err := ParseSignature("int[] twoSum(int[] nums, int target, string name)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var c Case
+	if err := json.Unmarshal([]byte(`{"input": [[2, 7, 11], 9, "a b"], "output": [0, 1]}`), &c); err != nil {
+		t.Fatal(err)
+	}
+	input, output, err := sig.EncodeCase(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if input != "3\n2\n7\n11\n9\n3\na b\n" {
+		t.Errorf("unexpected input %q", input)
+	}
+	if output != "2\n0\n1\n" {
+		t.Errorf("unexpected output %q", output)
+	}
+	c.Input = c.Input[:2]
+	if _, _, err := sig.EncodeCase(c); err == nil {
+		t.Errorf("expect error on missing argument")
+	}
+
+	sig, err = ParseSignature("double scale(double x)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(`{"input": [0.123456789], "output": 1.5}`), &c); err != nil {
+		t.Fatal(err)
+	}
+	if input, output, err = sig.EncodeCase(c); err != nil {
+		t.Fatal(err)
+	}
+	if input != "0.123456789\n" || output != "1.50000\n" {
+		t.Errorf("unexpected double case %q, %q", input, output)
+	}
+}
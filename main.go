@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/easyAation/scaffold/db"
 	"github.com/easyAation/scaffold/router"
@@ -12,6 +13,7 @@ import (
 
 	"online_judge/JudgeServer/common"
 	"online_judge/JudgeServer/route"
+	"online_judge/JudgeServer/sandbox"
 )
 
 var (
@@ -42,11 +44,20 @@ func init() {
 	if err := db.InitRedis(common.Config.Redis); err != nil {
 		panic(err)
 	}
+
+	if err := sandbox.ProtectProblemDir(); err != nil {
+		panic(err)
+	}
+	if err := sandbox.CheckSQLite(); err != nil {
+		panic(err)
+	}
+	go sandbox.CleanOutputs(time.Hour)
 }
 
 func main() {
 	engine := router.BuildHandler(optionsHandle, []router.MiddleWare{Cors}, route.JudgeRouteModule(),
-		route.AccountRouteModule(), route.ResourceRouteModule())
+		route.AccountRouteModule(), route.ResourceRouteModule(), route.PrepareRouteModule(), route.CLICSRouteModule(),
+		route.TeamRouteModule())
 	if err := engine.Run(":" + strconv.Itoa(common.Config.Listen)); err != nil {
 		panic(err)
 	}
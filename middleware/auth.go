@@ -37,6 +37,19 @@ func VerifyLogin(fn gin.HandlerFunc) gin.HandlerFunc {
 	}
 }
 
+// OptionalLogin sets the current user when the request carries a valid token, anonymous requests
+// are let through.
+func OptionalLogin(fn gin.HandlerFunc) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if token := ctx.GetHeader(common.AuthHeader); token != "" {
+			if userID, err := utils.GetUIDByToken(ctx, token); err == nil && userID != "" {
+				ctx.Set(currentUser, userID)
+			}
+		}
+		fn(ctx)
+	}
+}
+
 func GetCurrentID(ctx *gin.Context) string {
 	user, _ := ctx.Get(currentUser)
 	switch user.(type) {
@@ -48,3 +61,24 @@ func GetCurrentID(ctx *gin.Context) string {
 		return ""
 	}
 }
+
+// IsAdmin reports whether the user is listed in the admins of the config.
+func IsAdmin(uid string) bool {
+	for _, admin := range common.Config.Admins {
+		if admin == uid {
+			return true
+		}
+	}
+	return false
+}
+
+// VerifyAdmin only lets admins through, it should be used after VerifyLogin.
+func VerifyAdmin(fn gin.HandlerFunc) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if !IsAdmin(GetCurrentID(ctx)) {
+			reply.Err(errors.Errorf("permission denied."))(ctx)
+			return
+		}
+		fn(ctx)
+	}
+}
@@ -23,6 +23,9 @@ func (ac *Account) Valid() error {
 	if ac.ID == "" {
 		return errors.Errorf("Account ID cannot be empty")
 	}
+	if strings.HasPrefix(ac.ID, TeamEntrantPrefix) {
+		return errors.Errorf("Account ID cannot start with %s", TeamEntrantPrefix)
+	}
 	if ac.Name == "" {
 		return errors.Errorf("Account name cannot be empty")
 	}
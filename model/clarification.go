@@ -0,0 +1,151 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/easyAation/scaffold/db"
+	"github.com/pkg/errors"
+)
+
+const ClarificationTable = "clarification"
+
+// Clarification is a question of a contestant about a problem of the contest, or the whole
+// contest when PID is 0, with the answer of a judge. Announcements are posted by the judges
+// without question.
+type Clarification struct {
+	ID  int64  `json:"id" db:"id"`
+	CID int64  `json:"cid" db:"cid"`
+	PID int    `json:"pid" db:"pid"`
+	UID string `json:"uid" db:"uid"`
+	// Entrant is the contestant the asker takes part as, the asker or their team.
+	Entrant  string `json:"entrant" db:"entrant"`
+	Question string `json:"question" db:"question"`
+	Answer   string `json:"answer" db:"answer"`
+	// AnsweredBy is the judge who answered or announced.
+	AnsweredBy string `json:"answered_by" db:"answered_by"`
+	// Public clarifications are shown to every contestant, the others only to the entrant of the asker.
+	Public       bool      `json:"public" db:"public"`
+	Announcement bool      `json:"announcement" db:"announcement"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+}
+
+func (c *Clarification) Valid() error {
+	if c.CID == 0 {
+		return errors.Errorf("invalid contest id")
+	}
+	if c.PID < 0 {
+		return errors.Errorf("invalid pid")
+	}
+	if c.Announcement {
+		if c.Answer == "" || c.AnsweredBy == "" {
+			return errors.Errorf("invalid announcement")
+		}
+		return nil
+	}
+	if c.UID == "" {
+		return errors.Errorf("invalid uid")
+	}
+	if strings.TrimSpace(c.Question) == "" {
+		return errors.Errorf("invalid question")
+	}
+	return nil
+}
+
+// VisibleTo reports whether the user, taking part as entrant, can read the clarification. Judges
+// read all of them, the members of a team the questions of each other.
+func (c *Clarification) VisibleTo(uid, entrant string, judge bool) bool {
+	return judge || c.Public || c.Announcement || c.UID == uid || (c.Entrant != "" && c.Entrant == entrant)
+}
+
+func AddClarification(sqlExec *db.SqlExec, c Clarification) (int64, error) {
+	if c.Announcement {
+		c.Public = true
+	}
+	if err := c.Valid(); err != nil {
+		return 0, err
+	}
+	result, err := sqlExec.Exec("INSERT INTO clarification (cid, pid, uid, entrant, question, answer, answered_by, "+
+		"public, announcement) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		c.CID, c.PID, c.UID, c.Entrant, c.Question, c.Answer, c.AnsweredBy, c.Public, c.Announcement)
+	if err != nil {
+		return 0, errors.Wrap(err, "db error.")
+	}
+	return result.LastInsertId()
+}
+
+// AnswerClarification sets the answer of the question, public broadcasts it to every contestant.
+func AnswerClarification(sqlExec *db.SqlExec, id int64, answer, judge string, public bool) (int64, error) {
+	if strings.TrimSpace(answer) == "" {
+		return 0, errors.Errorf("invalid answer")
+	}
+	result, err := sqlExec.Exec("UPDATE clarification SET answer = ?, answered_by = ?, public = ? "+
+		"WHERE id = ? AND announcement = 0", answer, judge, public, id)
+	if err != nil {
+		return 0, errors.Wrap(err, "db error.")
+	}
+	return result.RowsAffected()
+}
+
+func GetClarifications(sqlExec *db.SqlExec, filters map[string]interface{}) ([]Clarification, error) {
+	placeHolder := make([]string, 0, len(filters))
+	for key, value := range filters {
+		placeHolder = append(placeHolder, fmt.Sprintf("%s='%v'", key, value))
+	}
+	sql := "SELECT * FROM " + ClarificationTable
+	if len(placeHolder) != 0 {
+		sql += " WHERE " + strings.Join(placeHolder, " AND ")
+	}
+	sql += " ORDER BY id"
+	fmt.Println(sql)
+	rows, err := sqlExec.Queryx(sql)
+	if err != nil {
+		return nil, err
+	}
+	clarifications := make([]Clarification, 0)
+	for rows.Next() {
+		var c Clarification
+		if err = rows.StructScan(&c); err != nil {
+			return nil, errors.Wrap(err, "scan clarification fail.")
+		}
+		clarifications = append(clarifications, c)
+	}
+	return clarifications, nil
+}
+
+func GetOneClarification(sqlExec *db.SqlExec, filters map[string]interface{}) (*Clarification, error) {
+	cs, err := GetClarifications(sqlExec, filters)
+	if err != nil {
+		return nil, err
+	}
+	if len(cs) != 1 {
+		return nil, errors.Errorf("expect one, but result is %d", len(cs))
+	}
+	return &cs[0], nil
+}
+
+// ClarificationsReadAt returns the last time the user read the clarifications of the contest, zero
+// if never.
+func ClarificationsReadAt(sqlExec *db.SqlExec, cid int64, uid string) (time.Time, error) {
+	var readAt []time.Time
+	err := sqlExec.Select(&readAt, "SELECT read_at FROM clarification_read WHERE cid = ? AND uid = ?", cid, uid)
+	if err != nil {
+		return time.Time{}, errors.Wrap(err, "db error.")
+	}
+	if len(readAt) == 0 {
+		return time.Time{}, nil
+	}
+	return readAt[0], nil
+}
+
+// MarkClarificationsRead marks the clarifications of the contest read by the user at the time.
+func MarkClarificationsRead(sqlExec *db.SqlExec, cid int64, uid string, at time.Time) error {
+	_, err := sqlExec.Exec("INSERT INTO clarification_read (cid, uid, read_at) VALUES (?, ?, ?) "+
+		"ON DUPLICATE KEY UPDATE read_at = VALUES(read_at)", cid, uid, at)
+	if err != nil {
+		return errors.Wrap(err, "db error.")
+	}
+	return nil
+}
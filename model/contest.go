@@ -8,18 +8,48 @@ import (
 
 	"github.com/easyAation/scaffold/db"
 	"github.com/pkg/errors"
+
+	"online_judge/JudgeServer/utils"
 )
 
 const ContestTable = "contest"
 
+// values of Contest.Encrypt, 0 is public too. Users register to public contests, are added to the
+// participants of private ones or join them with the invitation code, and give the password of
+// password contests.
+const (
+	PublicContest   = 1
+	PrivateContest  = 2
+	PasswordContest = 3
+)
+
+// standings modes of a contest.
+const (
+	ICPCMode = "icpc"
+	// marathon standings sum the relative points of optimization problems.
+	MarathonMode = "marathon"
+	// OI contests hide the results until the end, the last submission of a problem counts.
+	OIMode = "oi"
+	// IOI contests show the results, the best score of each subtask counts.
+	IOIMode = "ioi"
+)
+
 type Contest struct {
-	ID        int64     `json:"id" db:"id"`
-	Title     string    `json:"title" db:"title"`
-	Encrypt   int       `json:"encrypt" db:"encrypt"`
-	StartAt   time.Time `json:"start_at" db:"start_at"`
-	EndAt     time.Time `json:"end_at" db:"end_at"`
-	CreatedAt time.Time `json:"create_at" db:"created_at"`
-	UpdatedAt time.Time `json:"update_at" db:"updated_at"`
+	ID      int64     `json:"id" db:"id"`
+	Title   string    `json:"title" db:"title"`
+	Encrypt int       `json:"encrypt" db:"encrypt"`
+	StartAt time.Time `json:"start_at" db:"start_at"`
+	EndAt   time.Time `json:"end_at" db:"end_at"`
+	Pretest bool      `json:"pretest" db:"pretest"`
+	Mode    string    `json:"mode" db:"mode"`
+	// the scoreboard is frozen FreezeMinutes before the end until it is unfrozen.
+	FreezeMinutes int  `json:"freeze_minutes" db:"freeze_minutes"`
+	Unfrozen      bool `json:"unfrozen" db:"unfrozen"`
+	// Password is the hashed password of a password contest, see CheckPassword.
+	Password   string    `json:"-" db:"password"`
+	InviteCode string    `json:"-" db:"invite_code"`
+	CreatedAt  time.Time `json:"create_at" db:"created_at"`
+	UpdatedAt  time.Time `json:"update_at" db:"updated_at"`
 }
 
 func (c *Contest) Valid() error {
@@ -35,21 +65,69 @@ func (c *Contest) Valid() error {
 	if c.EndAt.IsZero() {
 		return errors.Errorf("invalid end time")
 	}
+	switch c.Mode {
+	case ICPCMode, MarathonMode, OIMode, IOIMode:
+	default:
+		return errors.Errorf("invalid mode %s", c.Mode)
+	}
+	switch c.Encrypt {
+	case 0, PublicContest, PrivateContest:
+	case PasswordContest:
+		if c.Password == "" {
+			return errors.Errorf("invalid password")
+		}
+	default:
+		return errors.Errorf("invalid encrypt %d", c.Encrypt)
+	}
+	if c.FreezeMinutes < 0 || time.Duration(c.FreezeMinutes)*time.Minute > c.EndAt.Sub(c.StartAt) {
+		return errors.Errorf("invalid freeze minutes")
+	}
 	return nil
 }
 
+// FreezeAt returns the time the scoreboard is frozen, zero if it is never frozen.
+func (c *Contest) FreezeAt() time.Time {
+	if c.FreezeMinutes == 0 {
+		return time.Time{}
+	}
+	return c.EndAt.Add(-time.Duration(c.FreezeMinutes) * time.Minute)
+}
+
+// Frozen reports whether the public scoreboard hides the results at the time.
+func (c *Contest) Frozen(now time.Time) bool {
+	return c.FreezeMinutes != 0 && !c.Unfrozen && !now.Before(c.FreezeAt())
+}
+
+// HidesResults reports whether the results of the submissions are hidden at the time.
+func (c *Contest) HidesResults(now time.Time) bool {
+	return c.Mode == OIMode && now.Before(c.EndAt)
+}
+
+// CheckPassword reports whether the password is the one of the password contest.
+func (c *Contest) CheckPassword(password string) bool {
+	return c.Encrypt == PasswordContest && utils.EncryptPassword(c.Title, password) == c.Password
+}
+
+// AddContest adds the contest, the password of a password contest is given in clear.
 func AddContest(ctx context.Context, c Contest) (int64, error) {
+	if c.Mode == "" {
+		c.Mode = ICPCMode
+	}
 	if err := c.Valid(); err != nil {
 		return 0, err
 	}
+	if c.Encrypt == PasswordContest {
+		c.Password = utils.EncryptPassword(c.Title, c.Password)
+	}
 	sqlExec, err := db.GetSqlExec(ctx, "problem")
 	if err != nil {
 		return 0, err
 	}
-	result, err := sqlExec.Exec("INSERT INTO contest (title, encrypt, start_at, end_at) VALUES (?, ?,  ?, ?)",
+	result, err := sqlExec.Exec("INSERT INTO contest (title, encrypt, start_at, end_at, pretest, mode, freeze_minutes, "+
+		"password, invite_code) VALUES (?, ?,  ?, ?, ?, ?, ?, ?, ?)",
 		c.Title,
 		c.Encrypt,
-		c.StartAt, c.EndAt)
+		c.StartAt, c.EndAt, c.Pretest, c.Mode, c.FreezeMinutes, c.Password, c.InviteCode)
 	if err != nil {
 		return 0, errors.Wrap(err, "db error.")
 	}
@@ -0,0 +1,89 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/easyAation/scaffold/db"
+	"github.com/pkg/errors"
+)
+
+const ContestParticipantTable = "contest_participant"
+
+// ContestParticipant is a user registered to a contest, or added to the list of a private one.
+type ContestParticipant struct {
+	ID        int64     `json:"id" db:"id"`
+	CID       int64     `json:"cid" db:"cid"`
+	UID       string    `json:"uid" db:"uid"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// AddContestParticipants adds the users to the contest, the ones already in are skipped.
+func AddContestParticipants(sqlExec *db.SqlExec, cid int64, uids []string) error {
+	tx, err := sqlExec.Beginx()
+	if err != nil {
+		return errors.Wrap(err, "db error.")
+	}
+	for _, uid := range uids {
+		if uid == "" {
+			tx.Rollback()
+			return errors.Errorf("invalid uid")
+		}
+		if _, err = tx.Exec("INSERT IGNORE INTO contest_participant (cid, uid) VALUES (?, ?)", cid, uid); err != nil {
+			tx.Rollback()
+			return errors.Wrap(err, "insert fail.")
+		}
+	}
+	return tx.Commit()
+}
+
+func DeleteContestParticipants(sqlExec *db.SqlExec, cid int64, uids []string) (int64, error) {
+	var rows int64
+	for _, uid := range uids {
+		result, err := sqlExec.Exec("DELETE FROM contest_participant WHERE cid = ? AND uid = ?", cid, uid)
+		if err != nil {
+			return rows, errors.Wrap(err, "db error.")
+		}
+		n, _ := result.RowsAffected()
+		rows += n
+	}
+	return rows, nil
+}
+
+func GetContestParticipants(sqlExec *db.SqlExec, filters map[string]interface{}) ([]ContestParticipant, error) {
+	placeHolder := make([]string, 0, len(filters))
+	for key, value := range filters {
+		placeHolder = append(placeHolder, fmt.Sprintf("%s='%v'", key, value))
+	}
+	sql := "SELECT * FROM " + ContestParticipantTable
+	if len(placeHolder) != 0 {
+		sql += " WHERE " + strings.Join(placeHolder, " AND ")
+	}
+	fmt.Println(sql)
+	rows, err := sqlExec.Queryx(sql)
+	if err != nil {
+		return nil, err
+	}
+	participants := make([]ContestParticipant, 0)
+	for rows.Next() {
+		var p ContestParticipant
+		if err = rows.StructScan(&p); err != nil {
+			return nil, errors.Wrap(err, "scan contest participant fail.")
+		}
+		participants = append(participants, p)
+	}
+	return participants, nil
+}
+
+func IsContestParticipant(sqlExec *db.SqlExec, cid int64, uid string) (bool, error) {
+	if uid == "" {
+		return false, nil
+	}
+	var count int
+	err := sqlExec.Get(&count, "SELECT COUNT(*) FROM contest_participant WHERE cid = ? AND uid = ?", cid, uid)
+	if err != nil {
+		return false, errors.Wrap(err, "db error.")
+	}
+	return count != 0, nil
+}
@@ -3,6 +3,7 @@ package model
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/easyAation/scaffold/db"
 	"github.com/pkg/errors"
@@ -10,9 +11,15 @@ import (
 
 const ContestSubmitTable = "contest_submit"
 
+// ContestSubmit is a submission of an entrant of the contest, an account or a team (see
+// TeamEntrant). Author is the account who submitted.
 type ContestSubmit struct {
 	Submit
 	CID int64 `json:"cid" db:"cid"`
+	// Upsolve is set on the submissions after the end of the contest, they are not ranked.
+	Upsolve bool `json:"upsolve" db:"upsolve"`
+	// Virtual is set on the upsolving submissions of a virtual participation.
+	Virtual bool `json:"virtual" db:"virtual"`
 }
 
 func (c *ContestSubmit) Valid() error {
@@ -22,14 +29,20 @@ func (c *ContestSubmit) Valid() error {
 	return c.Submit.Valid()
 }
 
+// AddContestSubmit stores the judged contest submission. CreatedAT is the submit time, taken
+// before judging so that the queue does not count in the penalty, now when unset.
 func AddContestSubmit(sqlExec *db.SqlExec, cs ContestSubmit) (int64, error) {
 	if err := cs.Valid(); err != nil {
 		return 0, errors.Wrap(err, "invalid submit")
 	}
+	if cs.CreatedAT.IsZero() {
+		cs.CreatedAT = time.Now()
+	}
 	result, err := sqlExec.Exec("INSERT INTO contest_submit (pid, uid, cid, submit_id, code, language, run_time, "+
-		"memory, result)"+
-		" VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)",
-		cs.PID, cs.UID, cs.CID, cs.SubmitID, cs.Code, cs.Language, cs.RunTime, cs.Memory, cs.Result)
+		"memory, result, score, upsolve, virtual, author, created_at)"+
+		" VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		cs.PID, cs.UID, cs.CID, cs.SubmitID, cs.Code, cs.Language, cs.RunTime, cs.Memory, cs.Result, cs.Score,
+		cs.Upsolve, cs.Virtual, cs.Author, cs.CreatedAT)
 	if err != nil {
 		return 0, errors.Wrap(err, "insert fail.")
 	}
@@ -61,3 +74,23 @@ func GetContestSubmit(sqlExec *db.SqlExec, filters map[string]interface{}) ([]Co
 	}
 	return css, nil
 }
+
+// UpdateContestSubmit updates the contest submit by its primary key.
+func UpdateContestSubmit(sqlExec *db.SqlExec, id int64, values map[string]interface{}) (int64, error) {
+	if len(values) == 0 {
+		return 0, errors.Errorf("invalid values. this is a empty values.")
+	}
+	placeHolder := make([]string, 0, len(values))
+	args := make([]interface{}, 0, len(values)+1)
+	for key, value := range values {
+		placeHolder = append(placeHolder, key+" = ?")
+		args = append(args, value)
+	}
+	args = append(args, id)
+	result, err := sqlExec.Exec(fmt.Sprintf("UPDATE %s SET %s WHERE id = ?", ContestSubmitTable,
+		strings.Join(placeHolder, " , ")), args...)
+	if err != nil {
+		return 0, errors.Wrap(err, "db error.")
+	}
+	return result.RowsAffected()
+}
@@ -14,25 +14,54 @@ const (
 	ProblemTable = "problem"
 )
 
+// problem types.
+const (
+	StandardProblem = "standard"
+	// the submissions of output-only problems are answer files, they are never compiled or run.
+	OutputOnlyProblem = "output_only"
+	// the checker of optimization problems prints a quality score, the points of a test are
+	// relative to the best score achieved.
+	OptimizationProblem = "optimization"
+	// the submissions of SQL problems are queries, their result sets are compared with the ones of
+	// the reference query.
+	SQLProblem = "sql"
+)
+
+// objectives of optimization problems.
+const (
+	Maximize = "max"
+	Minimize = "min"
+)
+
 type Problem struct {
-	ID             int64     `json:"id" db:"id"`
-	Name           string    `json:"name" db:"name"`
-	Author         string    `json:"author" db:"author"`
-	Status         string    `json:"status,omitempty" db:"status,omitempty"`
-	Difficulty     string    `json:"difficulty" db:"difficulty"`
-	CaseDataInput  string    `json:"case_data_input" db:"case_data_input"`
-	CaseDataOutput string    `json:"case_data_output" db:"case_data_output"`
-	Description    string    `json:"description" db:"description"`
-	InputDes       string    `json:"input_des" db:"input_des"`
-	OutputDes      string    `json:"output_des" db:"output_des"`
-	Hint           string    `json:"hint" db:"hint"`
-	Solve          int       `json:"solve"`
-	Submission     int       `json:"submission" db:"submission"`
-	TimeLimit      int64     `json:"time_limit" db:"time_limit"`
-	MemoryLimit    int64     `json:"memory_limit" db:"memory_limit"`
-	AuthorCode     string    `json:"author_code" db:"author_code"`
-	CreatedTime    time.Time `json:"create_time" db:"created_time"`
-	UpdatedTime    time.Time `json:"update_time" db:"updated_time"`
+	ID             int64  `json:"id" db:"id"`
+	Name           string `json:"name" db:"name"`
+	Type           string `json:"type" db:"type"`
+	Author         string `json:"author" db:"author"`
+	Status         string `json:"status,omitempty" db:"status,omitempty"`
+	Difficulty     string `json:"difficulty" db:"difficulty"`
+	CaseDataInput  string `json:"case_data_input" db:"case_data_input"`
+	CaseDataOutput string `json:"case_data_output" db:"case_data_output"`
+	Description    string `json:"description" db:"description"`
+	InputDes       string `json:"input_des" db:"input_des"`
+	OutputDes      string `json:"output_des" db:"output_des"`
+	Hint           string `json:"hint" db:"hint"`
+	Solve          int    `json:"solve"`
+	Submission     int    `json:"submission" db:"submission"`
+	TimeLimit      int64  `json:"time_limit" db:"time_limit"`
+	MemoryLimit    int64  `json:"memory_limit" db:"memory_limit"`
+	AuthorCode     string `json:"author_code" db:"author_code"`
+	GenScript      string `json:"-" db:"gen_script"` // the seeds reveal the tests, only shown to the author
+	Signature      string `json:"signature" db:"signature"`
+	// FileInput and FileOutput name the files read and written by the programs of file I/O
+	// problems, stdin and stdout are used when empty.
+	FileInput  string `json:"file_input" db:"file_input"`
+	FileOutput string `json:"file_output" db:"file_output"`
+	Objective  string `json:"objective" db:"objective"`
+	// OrderedResult compares the rows of SQL problems in order.
+	OrderedResult bool      `json:"ordered_result" db:"ordered_result"`
+	CreatedTime   time.Time `json:"create_time" db:"created_time"`
+	UpdatedTime   time.Time `json:"update_time" db:"updated_time"`
 }
 
 func (pro *Problem) Valid() error {
@@ -51,15 +80,44 @@ func (pro *Problem) Valid() error {
 	if pro.MemoryLimit == 0 {
 		return errors.Errorf("invalid memory limit")
 	}
+	switch pro.Type {
+	case StandardProblem, OutputOnlyProblem, SQLProblem:
+	case OptimizationProblem:
+		if pro.Objective != Maximize && pro.Objective != Minimize {
+			return errors.Errorf("invalid objective %s", pro.Objective)
+		}
+	default:
+		return errors.Errorf("invalid problem type %s", pro.Type)
+	}
+	return ValidFileIO(pro.FileInput, pro.FileOutput)
+}
+
+// ValidFileIO checks the file names of file I/O problems, they are plain file names in the
+// working directory and both or none are set.
+func ValidFileIO(input, output string) error {
+	if input == "" && output == "" {
+		return nil
+	}
+	for _, name := range []string{input, output} {
+		if name == "" || name == "." || name == ".." || strings.ContainsAny(name, "/\\ \t\n") {
+			return errors.Errorf("invalid file name %q", name)
+		}
+	}
+	if input == output {
+		return errors.Errorf("input and output file must differ")
+	}
 	return nil
 }
 
 func AddProblem(sqlExec *db.SqlExec, pro Problem) (int64, error) {
+	if pro.Type == "" {
+		pro.Type = StandardProblem
+	}
 	if err := pro.Valid(); err != nil {
 		return 0, err
 	}
-	result, err := sqlExec.Exec("INSERT INTO problem (id, name, author, status, difficulty, case_data_input, case_data_output, description, input_des, output_des, hint, time_limit,memory_limit) "+
-		"VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)", pro.ID, pro.Name, pro.Author, pro.Status, pro.Difficulty, pro.CaseDataInput, pro.CaseDataOutput, pro.Description, pro.InputDes, pro.OutputDes, pro.Hint, pro.TimeLimit, pro.MemoryLimit)
+	result, err := sqlExec.Exec("INSERT INTO problem (id, name, type, author, status, difficulty, case_data_input, case_data_output, description, input_des, output_des, hint, time_limit,memory_limit, file_input, file_output, objective, ordered_result) "+
+		"VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)", pro.ID, pro.Name, pro.Type, pro.Author, pro.Status, pro.Difficulty, pro.CaseDataInput, pro.CaseDataOutput, pro.Description, pro.InputDes, pro.OutputDes, pro.Hint, pro.TimeLimit, pro.MemoryLimit, pro.FileInput, pro.FileOutput, pro.Objective, pro.OrderedResult)
 	if err != nil {
 		return 0, errors.Wrap(err, "db error.")
 	}
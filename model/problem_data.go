@@ -1,13 +1,19 @@
 package model
 
 import (
+	"bytes"
+	"crypto/md5"
 	"database/sql"
 	"fmt"
+	"io/ioutil"
 	"log"
 	"strings"
 
 	"github.com/easyAation/scaffold/db"
+	"github.com/jmoiron/sqlx"
 	"github.com/pkg/errors"
+
+	"online_judge/JudgeServer/utils"
 )
 
 type DataFile struct {
@@ -22,10 +28,21 @@ type ProblemData struct {
 	OutputFile   string `json:"output_file" db:"output_file"`
 	MD5          string `json:"md5" db:"md5"`
 	MD5TrimSpace string `json:"md5_trim_space" db:"md5_trim_space"`
+	IsSample     bool   `json:"is_sample" db:"is_sample"`
+	IsPretest    bool   `json:"is_pretest" db:"is_pretest"`
+	// tests of the same non zero subtask only score when all of them pass.
+	Subtask int `json:"subtask" db:"subtask"`
 }
 
-func (proData *ProblemData) CalculMD5() {
-
+// CalculMD5 fills MD5 and MD5TrimSpace from the content of OutputFile.
+func (proData *ProblemData) CalculMD5() error {
+	data, err := ioutil.ReadFile(proData.OutputFile)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	proData.MD5 = utils.CovertMD5(md5.Sum(data))
+	proData.MD5TrimSpace = utils.CovertMD5(md5.Sum(bytes.TrimSpace(data)))
+	return nil
 }
 func AddProblemDatas(sqlExec *db.SqlExec, proDatas []ProblemData) (int64, error) {
 	fn := func() (int64, error) {
@@ -34,7 +51,8 @@ func AddProblemDatas(sqlExec *db.SqlExec, proDatas []ProblemData) (int64, error)
 		tx := sqlExec.MustBegin()
 		for _, proData := range proDatas {
 			rows, err = tx.NamedExec("INSERT INTO problem_data (id, pid, input_file, output_file, md5,"+
-				"md5_trim_space) VALUES (:id, :pid, :input_file, :output_file, :md5, :md5_trim_space)", &proData)
+				"md5_trim_space, is_sample, is_pretest, subtask) VALUES (:id, :pid, :input_file, :output_file, :md5, "+
+				":md5_trim_space, :is_sample, :is_pretest, :subtask)", &proData)
 			if err != nil {
 				return 0, errors.Wrap(err, "internal error.")
 			}
@@ -52,7 +70,7 @@ func GetProblemData(sqlExec *db.SqlExec, filter map[string]interface{}) ([]Probl
 	for k, v := range filter {
 		placeHolder = append(placeHolder, fmt.Sprintf("%s='%v'", k, v))
 	}
-	sql := "select * from problem_data where " + strings.Join(placeHolder, "and")
+	sql := "select * from problem_data where " + strings.Join(placeHolder, " AND ") + " ORDER BY id"
 	fmt.Println(sql)
 
 	rows, err := sqlExec.Queryx(sql)
@@ -71,3 +89,99 @@ func GetProblemData(sqlExec *db.SqlExec, filter map[string]interface{}) ([]Probl
 	}
 	return prodatas, nil
 }
+
+// DeleteProblemData removes all test data rows of the problem, the files are kept.
+func DeleteProblemData(sqlExec *db.SqlExec, pid int) (int64, error) {
+	result, err := sqlExec.Exec("DELETE FROM problem_data WHERE pid = ?", pid)
+	if err != nil {
+		return 0, errors.Wrap(err, "db error.")
+	}
+	return result.RowsAffected()
+}
+
+// ReplaceProblemDatas replaces the test data of the problem in one transaction. The tests keep the
+// flags (is_sample, is_pretest, subtask) of the replaced test with the same input file.
+func ReplaceProblemDatas(sqlExec *db.SqlExec, pid int, proDatas []ProblemData) error {
+	if len(proDatas) == 0 {
+		return errors.Errorf("empty test data")
+	}
+	tx, err := sqlExec.Beginx()
+	if err != nil {
+		return errors.Wrap(err, "db error.")
+	}
+	var old []ProblemData
+	if err = tx.Select(&old, "SELECT * FROM problem_data WHERE pid = ?", pid); err != nil {
+		tx.Rollback()
+		return errors.Wrap(err, "db error.")
+	}
+	flags := make(map[string]ProblemData, len(old))
+	for _, proData := range old {
+		flags[proData.InputFile] = proData
+	}
+	if _, err = tx.Exec("DELETE FROM problem_data WHERE pid = ?", pid); err != nil {
+		tx.Rollback()
+		return errors.Wrap(err, "db error.")
+	}
+	for _, proData := range proDatas {
+		if prev, ok := flags[proData.InputFile]; ok {
+			proData.IsSample, proData.IsPretest, proData.Subtask = prev.IsSample, prev.IsPretest, prev.Subtask
+		}
+		proData.ID, proData.PID = 0, pid
+		_, err = tx.NamedExec("INSERT INTO problem_data (pid, input_file, output_file, md5, md5_trim_space, "+
+			"is_sample, is_pretest, subtask) VALUES (:pid, :input_file, :output_file, :md5, :md5_trim_space, "+
+			":is_sample, :is_pretest, :subtask)", &proData)
+		if err != nil {
+			tx.Rollback()
+			return errors.Wrap(err, "insert fail.")
+		}
+	}
+	return errors.Wrap(tx.Commit(), "db error.")
+}
+
+// UpdateProblemDataMD5 stores the recomputed md5 of the problem data.
+func UpdateProblemDataMD5(sqlExec *db.SqlExec, proData ProblemData) (int64, error) {
+	result, err := sqlExec.Exec("UPDATE problem_data SET md5 = ?, md5_trim_space = ? WHERE id = ?",
+		proData.MD5, proData.MD5TrimSpace, proData.ID)
+	if err != nil {
+		return 0, errors.Wrap(err, "db error.")
+	}
+	return result.RowsAffected()
+}
+
+// SetProblemDataFlag sets the flag (is_sample or is_pretest) of the problem data.
+func SetProblemDataFlag(sqlExec *db.SqlExec, pid int, ids []int, flag string, value bool) (int64, error) {
+	if len(ids) == 0 {
+		return 0, errors.Errorf("empty ids")
+	}
+	if flag != "is_sample" && flag != "is_pretest" {
+		return 0, errors.Errorf("invalid flag %s", flag)
+	}
+	query, args, err := sqlx.In("UPDATE problem_data SET "+flag+" = ? WHERE pid = ? AND id IN (?)", value, pid, ids)
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	result, err := sqlExec.Exec(query, args...)
+	if err != nil {
+		return 0, errors.Wrap(err, "db error.")
+	}
+	return result.RowsAffected()
+}
+
+// SetProblemDataSubtask moves the problem data to the subtask, 0 removes them from their subtask.
+func SetProblemDataSubtask(sqlExec *db.SqlExec, pid int, ids []int, subtask int) (int64, error) {
+	if len(ids) == 0 {
+		return 0, errors.Errorf("empty ids")
+	}
+	if subtask < 0 {
+		return 0, errors.Errorf("invalid subtask %d", subtask)
+	}
+	query, args, err := sqlx.In("UPDATE problem_data SET subtask = ? WHERE pid = ? AND id IN (?)", subtask, pid, ids)
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	result, err := sqlExec.Exec(query, args...)
+	if err != nil {
+		return 0, errors.Wrap(err, "db error.")
+	}
+	return result.RowsAffected()
+}
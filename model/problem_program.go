@@ -0,0 +1,138 @@
+package model
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/easyAation/scaffold/db"
+	"github.com/pkg/errors"
+)
+
+const ProblemProgramTable = "problem_program"
+
+// programName restricts program names to plain file names, they are used as source file names.
+var programName = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.-]*$`)
+
+// program types of a problem.
+const (
+	GeneratorProgram = "generator"
+	ValidatorProgram = "validator"
+	SolutionProgram  = "solution"
+	// grader sources and headers are compiled together with the submissions of function problems.
+	GraderProgram = "grader"
+	HeaderProgram = "header"
+	// the checker is run as `checker input output answer` and accepts the output with exit code 0.
+	CheckerProgram = "checker"
+)
+
+// expected outcomes of a solution program.
+const (
+	TagAccept       = "AC"
+	TagWrongAnswer  = "WA"
+	TagTimeLimit    = "TLE"
+	TagMemoryLimit  = "MLE"
+	TagRuntimeError = "RE"
+	TagFailing      = "FAIL" // any verdict other than Accepted
+)
+
+// ProblemProgram is a source file uploaded by the problem setter, such as test generators,
+// the input validator or the reference solution.
+type ProblemProgram struct {
+	ID        int64     `json:"id" db:"id"`
+	PID       int       `json:"pid" db:"pid"`
+	Name      string    `json:"name" db:"name"`
+	Type      string    `json:"type" db:"type"`
+	Language  string    `json:"language" db:"language"`
+	Code      string    `json:"code" db:"code"`
+	Tag       string    `json:"tag" db:"tag"`
+	CreatedAT time.Time `json:"created_at" db:"created_at"`
+	UpdatedAT time.Time `json:"updated_at" db:"updated_at"`
+}
+
+func (p *ProblemProgram) Valid() error {
+	if p.PID == 0 {
+		return errors.Errorf("invalid pid")
+	}
+	if !programName.MatchString(p.Name) {
+		return errors.Errorf("invalid name")
+	}
+	switch p.Type {
+	case GeneratorProgram, ValidatorProgram, SolutionProgram, GraderProgram, HeaderProgram, CheckerProgram:
+	default:
+		return errors.Errorf("invalid program type %s", p.Type)
+	}
+	if p.Language == "" {
+		return errors.Errorf("language cannot be empty")
+	}
+	if p.Code == "" {
+		return errors.Errorf("invalid code")
+	}
+	if p.Type != SolutionProgram {
+		if p.Tag != "" {
+			return errors.Errorf("only solutions can be tagged")
+		}
+		return nil
+	}
+	switch p.Tag {
+	case TagAccept, TagWrongAnswer, TagTimeLimit, TagMemoryLimit, TagRuntimeError, TagFailing:
+	default:
+		return errors.Errorf("invalid solution tag %s", p.Tag)
+	}
+	return nil
+}
+
+// AddProblemProgram saves the program, an existing program with the same name is replaced.
+func AddProblemProgram(sqlExec *db.SqlExec, p ProblemProgram) (int64, error) {
+	if err := p.Valid(); err != nil {
+		return 0, err
+	}
+	result, err := sqlExec.Exec("INSERT INTO problem_program (pid, name, type, language, code, tag) "+
+		"VALUES (?, ?, ?, ?, ?, ?) ON DUPLICATE KEY UPDATE type = VALUES(type), language = VALUES(language), "+
+		"code = VALUES(code), tag = VALUES(tag)",
+		p.PID, p.Name, p.Type, p.Language, p.Code, p.Tag)
+	if err != nil {
+		return 0, errors.Wrap(err, "db error.")
+	}
+	return result.LastInsertId()
+}
+
+func GetProblemPrograms(sqlExec *db.SqlExec, filters map[string]interface{}) ([]ProblemProgram, error) {
+	placeHolder := make([]string, 0, len(filters))
+	for key, value := range filters {
+		placeHolder = append(placeHolder, fmt.Sprintf("%s='%v'", key, value))
+	}
+	sql := "SELECT * FROM " + ProblemProgramTable
+	if len(placeHolder) != 0 {
+		sql += " WHERE " + strings.Join(placeHolder, " AND ")
+	}
+	fmt.Println(sql)
+	rows, err := sqlExec.Queryx(sql)
+	if err != nil {
+		return nil, err
+	}
+	var programs []ProblemProgram
+	for rows.Next() {
+		var p ProblemProgram
+		if err = rows.StructScan(&p); err != nil {
+			return nil, errors.Wrap(err, "scan program fail.")
+		}
+		programs = append(programs, p)
+	}
+	return programs, nil
+}
+
+func GetOneProblemProgram(sqlExec *db.SqlExec, filters map[string]interface{}) (*ProblemProgram, error) {
+	programs, err := GetProblemPrograms(sqlExec, filters)
+	if err != nil {
+		return nil, err
+	}
+	if len(programs) == 0 {
+		return nil, errors.Errorf("program not found.")
+	}
+	if len(programs) != 1 {
+		return nil, errors.Errorf("expect one, but result is %d", len(programs))
+	}
+	return &programs[0], nil
+}
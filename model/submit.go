@@ -18,15 +18,17 @@ const (
 )
 
 type Submit struct {
-	ID        int64     `json:"id" db:"id"`
-	UID       string    `json:"uid" db:"uid"`
-	PID       int       `json:"pid" db:"pid"`
-	SubmitID  string    `json:"submit_id" db:"submit_id"`
-	Code      string    `json:"code" db:"code"`
-	Language  string    `json:"language" db:"language"`
-	RunTime   int64     `json:"run_time" db:"run_time"`
-	Memory    int64     `json:"memory" db:"memory"`
-	Result    string    `json:"result" db:"result"`
+	ID       int64  `json:"id" db:"id"`
+	UID      string `json:"uid" db:"uid"`
+	PID      int    `json:"pid" db:"pid"`
+	SubmitID string `json:"submit_id" db:"submit_id"`
+	Code     string `json:"code" db:"code"`
+	Language string `json:"language" db:"language"`
+	RunTime  int64  `json:"run_time" db:"run_time"`
+	Memory   int64  `json:"memory" db:"memory"`
+	Result   string `json:"result" db:"result"`
+	// Score is the percentage of the tests scored, only set for scored problems.
+	Score     float64   `json:"score" db:"score"`
 	Author    string    `json:"author" db:"author"`
 	CreatedAT time.Time `json:"created_at" db:"created_at"`
 	UpdateAT  time.Time `json:"updated_at" db:"updated_at"`
@@ -55,9 +57,9 @@ func AddSubmit(sqlExec *db.SqlExec, sm *Submit) (int64, error) {
 	if err := sm.Valid(); err != nil {
 		return 0, errors.Wrap(err, "invalid submit")
 	}
-	result, err := sqlExec.Exec("INSERT INTO submit (pid, uid, submit_id, code, language, run_time, memory, result, author)"+
-		" VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)", sm.PID, sm.UID, sm.SubmitID, sm.Code, sm.Language, sm.RunTime,
-		sm.Memory, sm.Result, sm.Author)
+	result, err := sqlExec.Exec("INSERT INTO submit (pid, uid, submit_id, code, language, run_time, memory, result, score, author)"+
+		" VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)", sm.PID, sm.UID, sm.SubmitID, sm.Code, sm.Language, sm.RunTime,
+		sm.Memory, sm.Result, sm.Score, sm.Author)
 	if err != nil {
 		return 0, errors.Wrap(err, "insert fail.")
 	}
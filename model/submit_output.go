@@ -0,0 +1,121 @@
+package model
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/easyAation/scaffold/db"
+	"github.com/pkg/errors"
+)
+
+const SubmitOutputTable = "submit_output"
+
+// SubmitOutput is the truncated output of a failed test case, kept for problem setters.
+type SubmitOutput struct {
+	ID        int64     `json:"id" db:"id"`
+	SubmitID  string    `json:"submit_id" db:"submit_id"`
+	CaseIndex int       `json:"case_index" db:"case_index"`
+	Status    string    `json:"status" db:"status"`
+	Output    string    `json:"output" db:"output"`
+	Stderr    string    `json:"stderr" db:"stderr"`
+	DiffPos   int64     `json:"diff_pos" db:"diff_pos"` // offset of the first differing byte, -1 if equal
+	CreatedAT time.Time `json:"created_at" db:"created_at"`
+}
+
+func AddSubmitOutputs(sqlExec *db.SqlExec, outputs []SubmitOutput) error {
+	if len(outputs) == 0 {
+		return nil
+	}
+	tx, err := sqlExec.Beginx()
+	if err != nil {
+		return errors.Wrap(err, "db error.")
+	}
+	for _, output := range outputs {
+		_, err = tx.NamedExec("INSERT INTO submit_output (submit_id, case_index, status, output, stderr, diff_pos) "+
+			"VALUES (:submit_id, :case_index, :status, :output, :stderr, :diff_pos)", &output)
+		if err != nil {
+			tx.Rollback()
+			return errors.Wrap(err, "insert fail.")
+		}
+	}
+	return tx.Commit()
+}
+
+// ReplaceSubmitOutputs replaces the kept outputs of the submission, when it is judged again.
+func ReplaceSubmitOutputs(sqlExec *db.SqlExec, submitID string, outputs []SubmitOutput) error {
+	tx, err := sqlExec.Beginx()
+	if err != nil {
+		return errors.Wrap(err, "db error.")
+	}
+	if _, err = tx.Exec("DELETE FROM submit_output WHERE submit_id = ?", submitID); err != nil {
+		tx.Rollback()
+		return errors.Wrap(err, "db error.")
+	}
+	for _, output := range outputs {
+		_, err = tx.NamedExec("INSERT INTO submit_output (submit_id, case_index, status, output, stderr, diff_pos) "+
+			"VALUES (:submit_id, :case_index, :status, :output, :stderr, :diff_pos)", &output)
+		if err != nil {
+			tx.Rollback()
+			return errors.Wrap(err, "insert fail.")
+		}
+	}
+	return tx.Commit()
+}
+
+func GetSubmitOutputs(sqlExec *db.SqlExec, filters map[string]interface{}) ([]SubmitOutput, error) {
+	placeHolder := make([]string, 0, len(filters))
+	for key, value := range filters {
+		placeHolder = append(placeHolder, fmt.Sprintf("%s='%v'", key, value))
+	}
+	sql := "SELECT * FROM " + SubmitOutputTable
+	if len(placeHolder) != 0 {
+		sql += " WHERE " + strings.Join(placeHolder, " AND ")
+	}
+	sql += " ORDER BY case_index"
+	fmt.Println(sql)
+	rows, err := sqlExec.Queryx(sql)
+	if err != nil {
+		return nil, err
+	}
+	var outputs []SubmitOutput
+	for rows.Next() {
+		var output SubmitOutput
+		if err = rows.StructScan(&output); err != nil {
+			return nil, errors.Wrap(err, "scan submit output fail.")
+		}
+		outputs = append(outputs, output)
+	}
+	return outputs, nil
+}
+
+// CleanSubmitOutputs removes the outputs created before the time, then removes the oldest ones
+// until the total size is under maxTotal bytes.
+func CleanSubmitOutputs(sqlExec *db.SqlExec, before time.Time, maxTotal int64) (int64, error) {
+	result, err := sqlExec.Exec("DELETE FROM submit_output WHERE created_at < ?", before)
+	if err != nil {
+		return 0, errors.Wrap(err, "db error.")
+	}
+	removed, _ := result.RowsAffected()
+	for maxTotal > 0 {
+		var total sql.NullInt64
+		err := sqlExec.Get(&total, "SELECT SUM(LENGTH(output) + LENGTH(stderr)) FROM submit_output")
+		if err != nil {
+			return removed, errors.Wrap(err, "db error.")
+		}
+		if total.Int64 <= maxTotal {
+			break
+		}
+		result, err := sqlExec.Exec("DELETE FROM submit_output ORDER BY id LIMIT 100")
+		if err != nil {
+			return removed, errors.Wrap(err, "db error.")
+		}
+		n, _ := result.RowsAffected()
+		if n == 0 {
+			break
+		}
+		removed += n
+	}
+	return removed, nil
+}
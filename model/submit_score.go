@@ -0,0 +1,71 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/easyAation/scaffold/db"
+	"github.com/pkg/errors"
+)
+
+const SubmitScoreTable = "submit_score"
+
+// SubmitScore is the raw score of a test case of an optimization problem, as printed by the checker.
+// Every test the submission was judged on has one, Raw is only set when Accepted.
+type SubmitScore struct {
+	ID       int64  `json:"id" db:"id"`
+	SubmitID string `json:"submit_id" db:"submit_id"`
+	PID      int    `json:"pid" db:"pid"`
+	// DataID is the id of the test case in problem_data.
+	DataID    int       `json:"data_id" db:"data_id"`
+	Accepted  bool      `json:"accepted" db:"accepted"`
+	Raw       float64   `json:"raw" db:"raw"`
+	CreatedAT time.Time `json:"created_at" db:"created_at"`
+}
+
+// SaveSubmitScores replaces the raw scores of the submission.
+func SaveSubmitScores(sqlExec *db.SqlExec, submitID string, scores []SubmitScore) error {
+	tx, err := sqlExec.Beginx()
+	if err != nil {
+		return errors.Wrap(err, "db error.")
+	}
+	if _, err = tx.Exec("DELETE FROM submit_score WHERE submit_id = ?", submitID); err != nil {
+		tx.Rollback()
+		return errors.Wrap(err, "delete fail.")
+	}
+	for _, score := range scores {
+		_, err = tx.NamedExec("INSERT INTO submit_score (submit_id, pid, data_id, accepted, raw) "+
+			"VALUES (:submit_id, :pid, :data_id, :accepted, :raw)", &score)
+		if err != nil {
+			tx.Rollback()
+			return errors.Wrap(err, "insert fail.")
+		}
+	}
+	return tx.Commit()
+}
+
+func GetSubmitScores(sqlExec *db.SqlExec, filters map[string]interface{}) ([]SubmitScore, error) {
+	placeHolder := make([]string, 0, len(filters))
+	for key, value := range filters {
+		placeHolder = append(placeHolder, fmt.Sprintf("%s='%v'", key, value))
+	}
+	sql := "SELECT * FROM " + SubmitScoreTable
+	if len(placeHolder) != 0 {
+		sql += " WHERE " + strings.Join(placeHolder, " AND ")
+	}
+	fmt.Println(sql)
+	rows, err := sqlExec.Queryx(sql)
+	if err != nil {
+		return nil, err
+	}
+	var scores []SubmitScore
+	for rows.Next() {
+		var score SubmitScore
+		if err = rows.StructScan(&score); err != nil {
+			return nil, errors.Wrap(err, "scan submit score fail.")
+		}
+		scores = append(scores, score)
+	}
+	return scores, nil
+}
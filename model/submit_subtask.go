@@ -0,0 +1,69 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/easyAation/scaffold/db"
+	"github.com/pkg/errors"
+)
+
+const SubmitSubtaskTable = "submit_subtask"
+
+// SubmitSubtask is the score of a submission on a subtask, see ProblemData.Subtask.
+type SubmitSubtask struct {
+	ID       int64  `json:"id" db:"id"`
+	SubmitID string `json:"submit_id" db:"submit_id"`
+	PID      int    `json:"pid" db:"pid"`
+	Subtask  int    `json:"subtask" db:"subtask"`
+	// Score is in percentage of the whole problem.
+	Score     float64   `json:"score" db:"score"`
+	CreatedAT time.Time `json:"created_at" db:"created_at"`
+}
+
+// SaveSubmitSubtasks replaces the subtask scores of the submission.
+func SaveSubmitSubtasks(sqlExec *db.SqlExec, submitID string, subtasks []SubmitSubtask) error {
+	tx, err := sqlExec.Beginx()
+	if err != nil {
+		return errors.Wrap(err, "db error.")
+	}
+	if _, err = tx.Exec("DELETE FROM submit_subtask WHERE submit_id = ?", submitID); err != nil {
+		tx.Rollback()
+		return errors.Wrap(err, "delete fail.")
+	}
+	for _, subtask := range subtasks {
+		_, err = tx.NamedExec("INSERT INTO submit_subtask (submit_id, pid, subtask, score) "+
+			"VALUES (:submit_id, :pid, :subtask, :score)", &subtask)
+		if err != nil {
+			tx.Rollback()
+			return errors.Wrap(err, "insert fail.")
+		}
+	}
+	return tx.Commit()
+}
+
+func GetSubmitSubtasks(sqlExec *db.SqlExec, filters map[string]interface{}) ([]SubmitSubtask, error) {
+	placeHolder := make([]string, 0, len(filters))
+	for key, value := range filters {
+		placeHolder = append(placeHolder, fmt.Sprintf("%s='%v'", key, value))
+	}
+	sql := "SELECT * FROM " + SubmitSubtaskTable
+	if len(placeHolder) != 0 {
+		sql += " WHERE " + strings.Join(placeHolder, " AND ")
+	}
+	fmt.Println(sql)
+	rows, err := sqlExec.Queryx(sql)
+	if err != nil {
+		return nil, err
+	}
+	var subtasks []SubmitSubtask
+	for rows.Next() {
+		var subtask SubmitSubtask
+		if err = rows.StructScan(&subtask); err != nil {
+			return nil, errors.Wrap(err, "scan submit subtask fail.")
+		}
+		subtasks = append(subtasks, subtask)
+	}
+	return subtasks, nil
+}
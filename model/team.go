@@ -0,0 +1,209 @@
+package model
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/easyAation/scaffold/db"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+const (
+	TeamTable       = "team"
+	TeamMemberTable = "team_member"
+	// MaxTeamMembers is the size limit of a team, as in ICPC.
+	MaxTeamMembers = 3
+	// TeamEntrantPrefix prefixes the ID of a team taking part in a contest, account IDs can not
+	// start with it.
+	TeamEntrantPrefix = "team-"
+)
+
+// Team groups accounts taking part in contests as a unit: it registers, submits and is ranked
+// under its entrant ID, see TeamEntrant.
+type Team struct {
+	ID        int64     `json:"id" db:"id"`
+	Name      string    `json:"name" db:"name"`
+	CreatedBy string    `json:"created_by" db:"created_by"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// TeamMember is a member of a team, or an invitation until Accepted. The creator is accepted.
+type TeamMember struct {
+	ID        int64     `json:"id" db:"id"`
+	TeamID    int64     `json:"team_id" db:"team_id"`
+	UID       string    `json:"uid" db:"uid"`
+	Accepted  bool      `json:"accepted" db:"accepted"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// TeamEntrant returns the ID of the team in contests, stored as the uid of its participations
+// and submissions.
+func TeamEntrant(teamID int64) string {
+	return TeamEntrantPrefix + strconv.FormatInt(teamID, 10)
+}
+
+func (t *Team) Valid() error {
+	if strings.TrimSpace(t.Name) == "" {
+		return errors.Errorf("invalid team name")
+	}
+	if t.CreatedBy == "" {
+		return errors.Errorf("invalid creator")
+	}
+	return nil
+}
+
+// AddTeam creates the team of the creator and invites the members.
+func AddTeam(sqlExec *db.SqlExec, t Team, members []string) (int64, error) {
+	if err := t.Valid(); err != nil {
+		return 0, err
+	}
+	members = uniqueMembers(append([]string{t.CreatedBy}, members...))
+	if len(members) > MaxTeamMembers {
+		return 0, errors.Errorf("a team has at most %d members", MaxTeamMembers)
+	}
+	tx, err := sqlExec.Beginx()
+	if err != nil {
+		return 0, errors.Wrap(err, "db error.")
+	}
+	result, err := tx.Exec("INSERT INTO team (name, created_by) VALUES (?, ?)", t.Name, t.CreatedBy)
+	if err != nil {
+		tx.Rollback()
+		return 0, errors.Wrap(err, "insert fail.")
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		tx.Rollback()
+		return 0, errors.Wrap(err, "db error.")
+	}
+	for _, uid := range members {
+		_, err = tx.Exec("INSERT INTO team_member (team_id, uid, accepted) VALUES (?, ?, ?)",
+			id, uid, uid == t.CreatedBy)
+		if err != nil {
+			tx.Rollback()
+			return 0, errors.Wrap(err, "insert fail.")
+		}
+	}
+	return id, tx.Commit()
+}
+
+func uniqueMembers(uids []string) []string {
+	seen := make(map[string]bool, len(uids))
+	list := make([]string, 0, len(uids))
+	for _, uid := range uids {
+		if uid != "" && !seen[uid] {
+			seen[uid] = true
+			list = append(list, uid)
+		}
+	}
+	return list
+}
+
+// UpdateTeamMembers sets the members of the team: the members not listed are removed, the new ones
+// are invited and the others are kept as they are. The creator stays in the team.
+func UpdateTeamMembers(sqlExec *db.SqlExec, t Team, members []string) error {
+	members = uniqueMembers(append([]string{t.CreatedBy}, members...))
+	if len(members) > MaxTeamMembers {
+		return errors.Errorf("a team has at most %d members", MaxTeamMembers)
+	}
+	tx, err := sqlExec.Beginx()
+	if err != nil {
+		return errors.Wrap(err, "db error.")
+	}
+	query, args, err := sqlx.In("DELETE FROM team_member WHERE team_id = ? AND uid NOT IN (?)", t.ID, members)
+	if err != nil {
+		tx.Rollback()
+		return errors.WithStack(err)
+	}
+	if _, err = tx.Exec(query, args...); err != nil {
+		tx.Rollback()
+		return errors.Wrap(err, "delete fail.")
+	}
+	for _, uid := range members {
+		if _, err = tx.Exec("INSERT IGNORE INTO team_member (team_id, uid) VALUES (?, ?)", t.ID, uid); err != nil {
+			tx.Rollback()
+			return errors.Wrap(err, "insert fail.")
+		}
+	}
+	return tx.Commit()
+}
+
+// AcceptTeam accepts the invitation of the user to the team.
+func AcceptTeam(sqlExec *db.SqlExec, teamID int64, uid string) (int64, error) {
+	result, err := sqlExec.Exec("UPDATE team_member SET accepted = 1 WHERE team_id = ? AND uid = ?", teamID, uid)
+	if err != nil {
+		return 0, errors.Wrap(err, "db error.")
+	}
+	return result.RowsAffected()
+}
+
+// LeaveTeam removes the user from the team, or declines the invitation.
+func LeaveTeam(sqlExec *db.SqlExec, teamID int64, uid string) (int64, error) {
+	result, err := sqlExec.Exec("DELETE FROM team_member WHERE team_id = ? AND uid = ?", teamID, uid)
+	if err != nil {
+		return 0, errors.Wrap(err, "db error.")
+	}
+	return result.RowsAffected()
+}
+
+func GetTeams(sqlExec *db.SqlExec, filters map[string]interface{}) ([]Team, error) {
+	placeHolder := make([]string, 0, len(filters))
+	for key, value := range filters {
+		placeHolder = append(placeHolder, fmt.Sprintf("%s='%v'", key, value))
+	}
+	sql := "SELECT * FROM " + TeamTable
+	if len(placeHolder) != 0 {
+		sql += " WHERE " + strings.Join(placeHolder, " AND ")
+	}
+	fmt.Println(sql)
+	rows, err := sqlExec.Queryx(sql)
+	if err != nil {
+		return nil, err
+	}
+	teams := make([]Team, 0)
+	for rows.Next() {
+		var t Team
+		if err = rows.StructScan(&t); err != nil {
+			return nil, errors.Wrap(err, "scan team fail.")
+		}
+		teams = append(teams, t)
+	}
+	return teams, nil
+}
+
+func GetTeamMembers(sqlExec *db.SqlExec, filters map[string]interface{}) ([]TeamMember, error) {
+	placeHolder := make([]string, 0, len(filters))
+	for key, value := range filters {
+		placeHolder = append(placeHolder, fmt.Sprintf("%s='%v'", key, value))
+	}
+	sql := "SELECT * FROM " + TeamMemberTable
+	if len(placeHolder) != 0 {
+		sql += " WHERE " + strings.Join(placeHolder, " AND ")
+	}
+	fmt.Println(sql)
+	rows, err := sqlExec.Queryx(sql)
+	if err != nil {
+		return nil, err
+	}
+	members := make([]TeamMember, 0)
+	for rows.Next() {
+		var m TeamMember
+		if err = rows.StructScan(&m); err != nil {
+			return nil, errors.Wrap(err, "scan team member fail.")
+		}
+		members = append(members, m)
+	}
+	return members, nil
+}
+
+// TeamIDsOf returns the teams the account is an accepted member of.
+func TeamIDsOf(sqlExec *db.SqlExec, uid string) ([]int64, error) {
+	ids := make([]int64, 0)
+	if err := sqlExec.Select(&ids, "SELECT team_id FROM team_member WHERE uid = ? AND accepted = 1", uid); err != nil {
+		return nil, errors.Wrap(err, "db error.")
+	}
+	return ids, nil
+}
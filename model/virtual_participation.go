@@ -0,0 +1,105 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/easyAation/scaffold/db"
+	"github.com/pkg/errors"
+)
+
+const VirtualParticipationTable = "virtual_participation"
+
+// VirtualParticipation is a personal replay of an ended contest: the user takes it during a window
+// of the contest duration from StartAt, and is ranked against the original contestants at the same
+// time of the contest.
+type VirtualParticipation struct {
+	ID        int64     `json:"id" db:"id"`
+	CID       int64     `json:"cid" db:"cid"`
+	UID       string    `json:"uid" db:"uid"`
+	StartAt   time.Time `json:"start_at" db:"start_at"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+func (v *VirtualParticipation) Valid() error {
+	if v.CID == 0 {
+		return errors.Errorf("invalid contest id")
+	}
+	if v.UID == "" {
+		return errors.Errorf("invalid uid")
+	}
+	if v.StartAt.IsZero() {
+		return errors.Errorf("invalid start time")
+	}
+	return nil
+}
+
+// EndAt returns the end of the window of the virtual participation.
+func (v *VirtualParticipation) EndAt(contest Contest) time.Time {
+	return v.StartAt.Add(contest.EndAt.Sub(contest.StartAt))
+}
+
+// Running reports whether now is in the window of the virtual participation.
+func (v *VirtualParticipation) Running(contest Contest, now time.Time) bool {
+	return !now.Before(v.StartAt) && now.Before(v.EndAt(contest))
+}
+
+// ContestTime maps a time of the virtual participation to the same time of the contest.
+func (v *VirtualParticipation) ContestTime(contest Contest, t time.Time) time.Time {
+	return contest.StartAt.Add(t.Sub(v.StartAt))
+}
+
+// AddVirtualParticipation starts the virtual participation, a user takes a contest virtually once.
+func AddVirtualParticipation(sqlExec *db.SqlExec, v VirtualParticipation) (int64, error) {
+	if err := v.Valid(); err != nil {
+		return 0, err
+	}
+	result, err := sqlExec.Exec("INSERT INTO virtual_participation (cid, uid, start_at) VALUES (?, ?, ?)",
+		v.CID, v.UID, v.StartAt)
+	if err != nil {
+		if strings.Contains(err.Error(), "Duplicate") {
+			return 0, errors.Errorf("%s already took contest %d virtually.", v.UID, v.CID)
+		}
+		return 0, errors.Wrap(err, "insert fail.")
+	}
+	return result.LastInsertId()
+}
+
+func GetVirtualParticipations(sqlExec *db.SqlExec, filters map[string]interface{}) ([]VirtualParticipation, error) {
+	placeHolder := make([]string, 0, len(filters))
+	for key, value := range filters {
+		placeHolder = append(placeHolder, fmt.Sprintf("%s='%v'", key, value))
+	}
+	sql := "SELECT * FROM " + VirtualParticipationTable
+	if len(placeHolder) != 0 {
+		sql += " WHERE " + strings.Join(placeHolder, " AND ")
+	}
+	fmt.Println(sql)
+	rows, err := sqlExec.Queryx(sql)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]VirtualParticipation, 0)
+	for rows.Next() {
+		var v VirtualParticipation
+		if err = rows.StructScan(&v); err != nil {
+			return nil, errors.Wrap(err, "scan virtual participation fail.")
+		}
+		list = append(list, v)
+	}
+	return list, nil
+}
+
+// GetVirtualParticipation returns the virtual participation of the user in the contest, nil if
+// there is none.
+func GetVirtualParticipation(sqlExec *db.SqlExec, cid int64, uid string) (*VirtualParticipation, error) {
+	list, err := GetVirtualParticipations(sqlExec, map[string]interface{}{
+		"cid": cid,
+		"uid": uid,
+	})
+	if err != nil || len(list) == 0 {
+		return nil, err
+	}
+	return &list[0], nil
+}
@@ -0,0 +1,252 @@
+package route
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/easyAation/scaffold/db"
+	"github.com/easyAation/scaffold/reply"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+
+	"online_judge/JudgeServer/middleware"
+	"online_judge/JudgeServer/model"
+	"online_judge/JudgeServer/stream"
+)
+
+// publishClarification pushes the clarification to the streams of its contest.
+func publishClarification(sqlExec *db.SqlExec, id int64) error {
+	c, err := model.GetOneClarification(sqlExec, map[string]interface{}{
+		"id": id,
+	})
+	if err != nil {
+		return err
+	}
+	stream.Default.Publish(stream.ClarificationTopic(c.CID), stream.Event{
+		Name: "clarification",
+		Data: *c,
+	})
+	return nil
+}
+
+// clarificationContest loads the contest of a clarification and checks the problem, if any, is
+// in the contest.
+func clarificationContest(sqlExec *db.SqlExec, cid int64, pid int) (*model.Contest, gin.HandlerFunc) {
+	contests, err := model.GetContest(sqlExec, map[string]interface{}{
+		"id": cid,
+	})
+	if err != nil {
+		return nil, reply.Err(err)
+	}
+	if len(contests) == 0 {
+		return nil, replyCode(http.StatusNotFound, CodeContestNotFound,
+			errors.Errorf("contest %d not found.", cid))
+	}
+	if pid != 0 {
+		cps, err := model.GetContestProblems(sqlExec, map[string]interface{}{
+			"cid": cid,
+			"pid": pid,
+		})
+		if err != nil {
+			return nil, reply.Err(err)
+		}
+		if len(cps) == 0 {
+			return nil, replyCode(http.StatusForbidden, CodeProblemNotInContest,
+				errors.Errorf("problem %d is not in contest %d.", pid, cid))
+		}
+	}
+	return &contests[0], nil
+}
+
+// askClarification asks a question about a problem of the contest, or the contest when pid is 0.
+// Questions are only accepted while the contest runs.
+func askClarification(ctx *gin.Context) gin.HandlerFunc {
+	var request model.Clarification
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		return reply.ErrorWithMessage(err, "invalid param")
+	}
+	sqlExec, err := db.GetSqlExec(ctx.Request.Context(), "problem")
+	if err != nil {
+		return reply.Err(err)
+	}
+	contest, rejected := clarificationContest(sqlExec, request.CID, request.PID)
+	if rejected != nil {
+		return rejected
+	}
+	now := time.Now()
+	if now.Before(contest.StartAt) || !now.Before(contest.EndAt) {
+		return replyCode(http.StatusForbidden, CodeContestNotStarted,
+			errors.Errorf("contest %d is not running.", request.CID))
+	}
+	uid := middleware.GetCurrentID(ctx)
+	ok, err := canParticipate(sqlExec, contest, uid)
+	if err != nil {
+		return reply.Err(err)
+	}
+	if !ok {
+		return replyCode(http.StatusForbidden, CodeNotParticipant,
+			errors.Errorf("you can not participate in contest %d.", request.CID))
+	}
+	entrant, _, err := contestEntrant(sqlExec, contest.ID, uid)
+	if err != nil {
+		return reply.Err(err)
+	}
+
+	id, err := model.AddClarification(sqlExec, model.Clarification{
+		CID:      request.CID,
+		PID:      request.PID,
+		UID:      uid,
+		Entrant:  entrant,
+		Question: request.Question,
+	})
+	if err != nil {
+		return reply.Err(err)
+	}
+	if err := publishClarification(sqlExec, id); err != nil {
+		return reply.Err(err)
+	}
+	return reply.Success(http.StatusOK, map[string]interface{}{
+		"id": id,
+	})
+}
+
+// answerClarification answers a question, privately to the asker unless public is set.
+func answerClarification(ctx *gin.Context) gin.HandlerFunc {
+	var (
+		request = struct {
+			ID     int64  `json:"id"`
+			Answer string `json:"answer"`
+			Public bool   `json:"public"`
+		}{}
+	)
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		return reply.ErrorWithMessage(err, "invalid param")
+	}
+	sqlExec, err := db.GetSqlExec(ctx.Request.Context(), "problem")
+	if err != nil {
+		return reply.Err(err)
+	}
+	rows, err := model.AnswerClarification(sqlExec, request.ID, request.Answer, middleware.GetCurrentID(ctx),
+		request.Public)
+	if err != nil {
+		return reply.Err(err)
+	}
+	if rows == 0 {
+		return reply.Err(errors.Errorf("question %d not found.", request.ID))
+	}
+	if err := publishClarification(sqlExec, request.ID); err != nil {
+		return reply.Err(err)
+	}
+	return reply.Success(http.StatusOK, nil)
+}
+
+// announce posts an announcement of the judges, shown on the contest page.
+func announce(ctx *gin.Context) gin.HandlerFunc {
+	var (
+		request = struct {
+			CID  int64  `json:"cid"`
+			PID  int    `json:"pid"`
+			Text string `json:"text"`
+		}{}
+	)
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		return reply.ErrorWithMessage(err, "invalid param")
+	}
+	sqlExec, err := db.GetSqlExec(ctx.Request.Context(), "problem")
+	if err != nil {
+		return reply.Err(err)
+	}
+	if _, rejected := clarificationContest(sqlExec, request.CID, request.PID); rejected != nil {
+		return rejected
+	}
+	id, err := model.AddClarification(sqlExec, model.Clarification{
+		CID:          request.CID,
+		PID:          request.PID,
+		Answer:       request.Text,
+		AnsweredBy:   middleware.GetCurrentID(ctx),
+		Announcement: true,
+	})
+	if err != nil {
+		return reply.Err(err)
+	}
+	if err := publishClarification(sqlExec, id); err != nil {
+		return reply.Err(err)
+	}
+	return reply.Success(http.StatusOK, map[string]interface{}{
+		"id": id,
+	})
+}
+
+// listClarifications returns the clarifications of the contest the user can read. For contestants
+// the answers since their last read are unread, for judges the questions waiting for an answer.
+func listClarifications(ctx *gin.Context) gin.HandlerFunc {
+	cid, err := strconv.ParseInt(ctx.Query("cid"), 10, 64)
+	if err != nil {
+		return reply.Err(errors.Errorf("invalid param cid: %v", ctx.Query("cid")))
+	}
+	sqlExec, err := db.GetSqlExec(ctx.Request.Context(), "problem")
+	if err != nil {
+		return reply.Err(err)
+	}
+	uid := middleware.GetCurrentID(ctx)
+	judge := middleware.IsAdmin(uid)
+	entrant, _, err := contestEntrant(sqlExec, cid, uid)
+	if err != nil {
+		return reply.Err(err)
+	}
+	readAt, err := model.ClarificationsReadAt(sqlExec, cid, uid)
+	if err != nil {
+		return reply.Err(err)
+	}
+	all, err := model.GetClarifications(sqlExec, map[string]interface{}{
+		"cid": cid,
+	})
+	if err != nil {
+		return reply.Err(err)
+	}
+	type item struct {
+		model.Clarification
+		Unread bool `json:"unread"`
+	}
+	var (
+		list   = make([]item, 0, len(all))
+		unread int
+	)
+	for _, c := range all {
+		if !c.VisibleTo(uid, entrant, judge) {
+			continue
+		}
+		i := item{Clarification: c}
+		if judge {
+			i.Unread = !c.Announcement && c.Answer == ""
+		} else {
+			i.Unread = c.Answer != "" && c.UpdatedAt.After(readAt)
+		}
+		if i.Unread {
+			unread++
+		}
+		list = append(list, i)
+	}
+	return reply.Success(http.StatusOK, map[string]interface{}{
+		"list":   list,
+		"total":  len(list),
+		"unread": unread,
+	})
+}
+
+// readClarifications marks the clarifications of the contest read.
+func readClarifications(ctx *gin.Context) gin.HandlerFunc {
+	cid, err := strconv.ParseInt(ctx.Query("cid"), 10, 64)
+	if err != nil {
+		return reply.Err(errors.Errorf("invalid param cid: %v", ctx.Query("cid")))
+	}
+	sqlExec, err := db.GetSqlExec(ctx.Request.Context(), "problem")
+	if err != nil {
+		return reply.Err(err)
+	}
+	if err := model.MarkClarificationsRead(sqlExec, cid, middleware.GetCurrentID(ctx), time.Now()); err != nil {
+		return reply.Err(err)
+	}
+	return reply.Success(http.StatusOK, nil)
+}
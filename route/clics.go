@@ -0,0 +1,330 @@
+package route
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/easyAation/scaffold/db"
+	"github.com/easyAation/scaffold/reply"
+	"github.com/easyAation/scaffold/router"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+
+	"online_judge/JudgeServer/clics"
+	"online_judge/JudgeServer/middleware"
+	"online_judge/JudgeServer/model"
+	"online_judge/JudgeServer/scoreboard"
+	"online_judge/JudgeServer/stream"
+)
+
+// CLICSRouteModule exposes the contests through the ICPC Contest API, for admins only since the
+// judgements are never frozen.
+func CLICSRouteModule() router.ModuleRoute {
+	routes := []*router.Router{
+		router.NewRouter("/api/contests/:id", http.MethodGet, reply.Wrap(clicsContest)),
+		router.NewRouter("/api/contests/:id/judgement-types", http.MethodGet, reply.Wrap(clicsJudgementTypes)),
+		router.NewRouter("/api/contests/:id/problems", http.MethodGet, reply.Wrap(clicsProblems)),
+		router.NewRouter("/api/contests/:id/teams", http.MethodGet, reply.Wrap(clicsTeams)),
+		router.NewRouter("/api/contests/:id/submissions", http.MethodGet, reply.Wrap(clicsSubmissions)),
+		router.NewRouter("/api/contests/:id/judgements", http.MethodGet, reply.Wrap(clicsJudgements)),
+		router.NewRouter("/api/contests/:id/scoreboard", http.MethodGet, reply.Wrap(clicsScoreboard)),
+		router.NewRouter("/api/contests/:id/event-feed", http.MethodGet, reply.Wrap(clicsEventFeed)),
+	}
+	return router.ModuleRoute{
+		MiddleWares: []router.MiddleWare{middleware.VerifyAdmin, middleware.VerifyLogin},
+		Routers:     routes,
+	}
+}
+
+// clicsFeed holds the contest objects converted to the Contest API.
+type clicsFeed struct {
+	sqlExec *db.SqlExec
+	contest *model.Contest
+}
+
+func loadCLICS(ctx *gin.Context) (*clicsFeed, gin.HandlerFunc) {
+	sqlExec, err := db.GetSqlExec(ctx.Request.Context(), "problem")
+	if err != nil {
+		return nil, reply.Err(err)
+	}
+	contests, err := model.GetContest(sqlExec, map[string]interface{}{
+		"id": ctx.Param("id"),
+	})
+	if err != nil {
+		return nil, reply.Err(err)
+	}
+	if len(contests) == 0 {
+		return nil, replyCode(http.StatusNotFound, CodeContestNotFound,
+			errors.Errorf("contest %s not found.", ctx.Param("id")))
+	}
+	return &clicsFeed{sqlExec: sqlExec, contest: &contests[0]}, nil
+}
+
+func clicsJSON(v interface{}) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.JSON(http.StatusOK, v)
+	}
+}
+
+func (f *clicsFeed) problems() ([]clics.Problem, error) {
+	pids, err := scoreboard.ContestPIDs(f.sqlExec, f.contest.ID)
+	if err != nil {
+		return nil, err
+	}
+	problems := make([]clics.Problem, 0, len(pids))
+	for position, pid := range pids {
+		problem, err := model.GetOneProblem(f.sqlExec, map[string]interface{}{
+			"id": pid,
+		})
+		if err != nil {
+			return nil, err
+		}
+		tests, err := model.GetProblemData(f.sqlExec, map[string]interface{}{
+			"pid": pid,
+		})
+		if err != nil {
+			return nil, err
+		}
+		problems = append(problems, clics.NewProblem(*problem, position, len(tests)))
+	}
+	return problems, nil
+}
+
+// submits returns the contest submissions in submission order, upsolving is not part of the contest.
+func (f *clicsFeed) submits() ([]model.ContestSubmit, error) {
+	all, err := model.GetContestSubmit(f.sqlExec, map[string]interface{}{
+		"cid": f.contest.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	submits := make([]model.ContestSubmit, 0, len(all))
+	for _, submit := range all {
+		if !submit.Upsolve {
+			submits = append(submits, submit)
+		}
+	}
+	sort.Slice(submits, func(i, j int) bool {
+		return submits[i].ID < submits[j].ID
+	})
+	return submits, nil
+}
+
+// teams returns the registered entrants of the contest, accounts or teams, with the ones who
+// submitted without being registered so that every submission has its team.
+func (f *clicsFeed) teams(ctx *gin.Context, submits []model.ContestSubmit) ([]clics.Team, error) {
+	names, err := entrantNames(ctx, f.sqlExec)
+	if err != nil {
+		return nil, err
+	}
+	participants, err := model.GetContestParticipants(f.sqlExec, map[string]interface{}{
+		"cid": f.contest.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(participants, func(i, j int) bool {
+		return participants[i].ID < participants[j].ID
+	})
+	var (
+		teams = make([]clics.Team, 0, len(participants))
+		seen  = make(map[string]bool)
+	)
+	add := func(uid string) {
+		if !seen[uid] {
+			seen[uid] = true
+			teams = append(teams, clics.Team{ID: uid, Name: names[uid]})
+		}
+	}
+	for _, p := range participants {
+		add(p.UID)
+	}
+	for _, submit := range submits {
+		add(submit.UID)
+	}
+	return teams, nil
+}
+
+func clicsContest(ctx *gin.Context) gin.HandlerFunc {
+	f, rejected := loadCLICS(ctx)
+	if rejected != nil {
+		return rejected
+	}
+	return clicsJSON(clics.NewContest(*f.contest, scoreboard.PenaltyMinutes()))
+}
+
+func clicsJudgementTypes(ctx *gin.Context) gin.HandlerFunc {
+	if _, rejected := loadCLICS(ctx); rejected != nil {
+		return rejected
+	}
+	return clicsJSON(clics.JudgementTypes())
+}
+
+func clicsProblems(ctx *gin.Context) gin.HandlerFunc {
+	f, rejected := loadCLICS(ctx)
+	if rejected != nil {
+		return rejected
+	}
+	problems, err := f.problems()
+	if err != nil {
+		return reply.Err(err)
+	}
+	return clicsJSON(problems)
+}
+
+func clicsTeams(ctx *gin.Context) gin.HandlerFunc {
+	f, rejected := loadCLICS(ctx)
+	if rejected != nil {
+		return rejected
+	}
+	submits, err := f.submits()
+	if err != nil {
+		return reply.Err(err)
+	}
+	teams, err := f.teams(ctx, submits)
+	if err != nil {
+		return reply.Err(err)
+	}
+	return clicsJSON(teams)
+}
+
+func clicsSubmissions(ctx *gin.Context) gin.HandlerFunc {
+	f, rejected := loadCLICS(ctx)
+	if rejected != nil {
+		return rejected
+	}
+	submits, err := f.submits()
+	if err != nil {
+		return reply.Err(err)
+	}
+	submissions := make([]clics.Submission, 0, len(submits))
+	for _, submit := range submits {
+		submissions = append(submissions, clics.NewSubmission(*f.contest, submit))
+	}
+	return clicsJSON(submissions)
+}
+
+func clicsJudgements(ctx *gin.Context) gin.HandlerFunc {
+	f, rejected := loadCLICS(ctx)
+	if rejected != nil {
+		return rejected
+	}
+	submits, err := f.submits()
+	if err != nil {
+		return reply.Err(err)
+	}
+	judgements := make([]clics.Judgement, 0, len(submits))
+	for _, submit := range submits {
+		judgements = append(judgements, clics.NewJudgement(*f.contest, submit))
+	}
+	return clicsJSON(judgements)
+}
+
+func clicsScoreboard(ctx *gin.Context) gin.HandlerFunc {
+	f, rejected := loadCLICS(ctx)
+	if rejected != nil {
+		return rejected
+	}
+	standings, err := scoreboard.LoadICPC(f.sqlExec, *f.contest, nil, false)
+	if err != nil {
+		return reply.Err(err)
+	}
+	return clicsJSON(clics.NewScoreboard(*f.contest, standings, time.Now()))
+}
+
+// clicsEventFeed writes the contest objects as NDJSON events. With `stream=true` the feed stays
+// open and sends the new submissions and judgements, and a newline on every heartbeat.
+func clicsEventFeed(ctx *gin.Context) gin.HandlerFunc {
+	f, rejected := loadCLICS(ctx)
+	if rejected != nil {
+		return rejected
+	}
+	problems, err := f.problems()
+	if err != nil {
+		return reply.Err(err)
+	}
+	var (
+		events  <-chan stream.Event
+		cancel  = func() {}
+		follow  = ctx.Query("stream") == "true"
+		changed = true
+	)
+	if follow {
+		events, cancel = stream.Default.Subscribe(stream.ContestTopic(f.contest.ID))
+	}
+
+	return func(ctx *gin.Context) {
+		defer cancel()
+		var (
+			token      int
+			teams      = make(map[string]bool)
+			judgements = make(map[string]string)
+			ticker     = time.NewTicker(heartbeat)
+			encoder    = json.NewEncoder(ctx.Writer)
+		)
+		defer ticker.Stop()
+		send := func(typ, op string, data interface{}) {
+			token++
+			encoder.Encode(clics.Event{Type: typ, ID: strconv.Itoa(token), Op: op, Data: data})
+		}
+		ctx.Header("Content-Type", "application/x-ndjson")
+		ctx.Status(http.StatusOK)
+		send("contests", "create", clics.NewContest(*f.contest, scoreboard.PenaltyMinutes()))
+		for _, t := range clics.JudgementTypes() {
+			send("judgement-types", "create", t)
+		}
+		for _, p := range problems {
+			send("problems", "create", p)
+		}
+		ctx.Stream(func(w io.Writer) bool {
+			if changed {
+				changed = false
+				submits, err := f.submits()
+				if err != nil {
+					return false
+				}
+				newTeams, err := f.teams(ctx, submits)
+				if err != nil {
+					return false
+				}
+				for _, team := range newTeams {
+					if !teams[team.ID] {
+						teams[team.ID] = true
+						send("teams", "create", team)
+					}
+				}
+				for _, submit := range submits {
+					j := clics.NewJudgement(*f.contest, submit)
+					typ := ""
+					if j.JudgementTypeID != nil {
+						typ = *j.JudgementTypeID
+					}
+					last, sent := judgements[submit.SubmitID]
+					if !sent {
+						send("submissions", "create", clics.NewSubmission(*f.contest, submit))
+					}
+					if !sent {
+						send("judgements", "create", j)
+					} else if last != typ {
+						send("judgements", "update", j)
+					}
+					judgements[submit.SubmitID] = typ
+				}
+				return follow
+			}
+			select {
+			case <-ctx.Request.Context().Done():
+				return false
+			case <-events:
+				changed = true
+			case <-ticker.C:
+				io.WriteString(w, "\n")
+			}
+			return true
+		})
+	}
+}
@@ -0,0 +1,449 @@
+package route
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/easyAation/scaffold/db"
+	"github.com/easyAation/scaffold/reply"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+
+	"online_judge/JudgeServer/common"
+	"online_judge/JudgeServer/middleware"
+	"online_judge/JudgeServer/model"
+	"online_judge/JudgeServer/scoreboard"
+	"online_judge/JudgeServer/stream"
+)
+
+// error codes of rejected contest submissions.
+const (
+	CodeContestNotFound     = 4001
+	CodeContestNotStarted   = 4002
+	CodeProblemNotInContest = 4003
+	CodeNotParticipant      = 4004
+	CodeNotAuthor           = 4005
+)
+
+func replyCode(status, code int, err error) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.JSON(status, reply.Response{
+			Code: code,
+			Msg:  err.Error(),
+		})
+	}
+}
+
+// contestEntrant returns who the user takes part in the contest as: the user when registered
+// alone, else the registered team of the user. ok is false when neither is registered.
+func contestEntrant(sqlExec *db.SqlExec, cid int64, uid string) (entrant string, ok bool, err error) {
+	if uid == "" {
+		return "", false, nil
+	}
+	if ok, err = model.IsContestParticipant(sqlExec, cid, uid); err != nil || ok {
+		return uid, ok, err
+	}
+	teams, err := model.TeamIDsOf(sqlExec, uid)
+	if err != nil {
+		return "", false, err
+	}
+	for _, id := range teams {
+		if ok, err = model.IsContestParticipant(sqlExec, cid, model.TeamEntrant(id)); err != nil || ok {
+			return model.TeamEntrant(id), ok, err
+		}
+	}
+	return uid, false, nil
+}
+
+// canParticipate reports whether the user may submit to the contest: admins and participants,
+// alone or in a team.
+func canParticipate(sqlExec *db.SqlExec, contest *model.Contest, uid string) (bool, error) {
+	if middleware.IsAdmin(uid) {
+		return true, nil
+	}
+	_, ok, err := contestEntrant(sqlExec, contest.ID, uid)
+	return ok, err
+}
+
+// entrantNames returns the names shown in the standings: account names and team names.
+func entrantNames(ctx context.Context, sqlExec *db.SqlExec) (map[string]string, error) {
+	accounts, err := model.GetAccounts(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	teams, err := model.GetTeams(sqlExec, nil)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]string, len(accounts)+len(teams))
+	for _, ac := range accounts {
+		names[ac.ID] = ac.Name
+	}
+	for _, t := range teams {
+		names[model.TeamEntrant(t.ID)] = t.Name
+	}
+	return names, nil
+}
+
+// canViewProblems reports whether the user may read the problems of the contest, they are kept
+// to the participants from the start until the end.
+func canViewProblems(sqlExec *db.SqlExec, contest *model.Contest, uid string) (bool, error) {
+	now := time.Now()
+	if middleware.IsAdmin(uid) || !now.Before(contest.EndAt) {
+		return true, nil
+	}
+	if now.Before(contest.StartAt) {
+		return false, nil
+	}
+	_, ok, err := contestEntrant(sqlExec, contest.ID, uid)
+	return ok, err
+}
+
+// hiddenProblems returns the problems of the contests not ended the user may not read.
+func hiddenProblems(sqlExec *db.SqlExec, uid string) (map[int64]bool, error) {
+	hidden := make(map[int64]bool)
+	if middleware.IsAdmin(uid) {
+		return hidden, nil
+	}
+	contests, err := model.GetContest(sqlExec, nil)
+	if err != nil {
+		return nil, err
+	}
+	visible := make(map[int64]bool)
+	for i := range contests {
+		if !time.Now().Before(contests[i].EndAt) {
+			continue
+		}
+		ok, err := canViewProblems(sqlExec, &contests[i], uid)
+		if err != nil {
+			return nil, err
+		}
+		cps, err := model.GetContestProblems(sqlExec, map[string]interface{}{
+			"cid": contests[i].ID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, cp := range cps {
+			if ok {
+				visible[cp.PID] = true
+			} else {
+				hidden[cp.PID] = true
+			}
+		}
+	}
+	for pid := range visible {
+		delete(hidden, pid)
+	}
+	return hidden, nil
+}
+
+// problemVisible reports whether the user may read and submit the problem outside of a contest,
+// the author always may.
+func problemVisible(sqlExec *db.SqlExec, problem *model.Problem, uid string) (bool, error) {
+	if uid != "" && problem.Author == uid {
+		return true, nil
+	}
+	hidden, err := hiddenProblems(sqlExec, uid)
+	if err != nil {
+		return false, err
+	}
+	return !hidden[problem.ID], nil
+}
+
+// checkContestSubmit checks that the contest has started, the problem belongs to it and, until the
+// end, that the user may participate. The submissions after the end are upsolving, open to all.
+// The returned handler is set when the submission is rejected.
+func checkContestSubmit(ctx *gin.Context, sqlExec *db.SqlExec, cid int64, pid int) (*model.Contest, bool, gin.HandlerFunc) {
+	contests, err := model.GetContest(sqlExec, map[string]interface{}{
+		"id": cid,
+	})
+	if err != nil {
+		return nil, false, reply.Err(err)
+	}
+	if len(contests) == 0 {
+		return nil, false, replyCode(http.StatusNotFound, CodeContestNotFound,
+			errors.Errorf("contest %d not found.", cid))
+	}
+	contest := &contests[0]
+	now := time.Now()
+	if now.Before(contest.StartAt) {
+		return nil, false, replyCode(http.StatusForbidden, CodeContestNotStarted,
+			errors.Errorf("contest %d has not started.", cid))
+	}
+	cps, err := model.GetContestProblems(sqlExec, map[string]interface{}{
+		"cid": cid,
+		"pid": pid,
+	})
+	if err != nil {
+		return nil, false, reply.Err(err)
+	}
+	if len(cps) == 0 {
+		return nil, false, replyCode(http.StatusForbidden, CodeProblemNotInContest,
+			errors.Errorf("problem %d is not in contest %d.", pid, cid))
+	}
+	if !now.Before(contest.EndAt) {
+		return contest, true, nil
+	}
+	ok, err := canParticipate(sqlExec, contest, middleware.GetCurrentID(ctx))
+	if err != nil {
+		return nil, false, reply.Err(err)
+	}
+	if !ok {
+		return nil, false, replyCode(http.StatusForbidden, CodeNotParticipant,
+			errors.Errorf("you can not participate in contest %d.", cid))
+	}
+	return contest, false, nil
+}
+
+// hidesResults reports whether the results of the contest are hidden from the user.
+func hidesResults(contest *model.Contest, uid string) bool {
+	return contest.HidesResults(time.Now()) && !middleware.IsAdmin(uid)
+}
+
+// frozenSubmit reports whether the result of the contest submission made at the time is hidden by
+// the scoreboard freeze, upsolving is never frozen.
+func frozenSubmit(contest *model.Contest, upsolve bool, at time.Time) bool {
+	return contest.Mode == model.ICPCMode && !upsolve && contest.Frozen(time.Now()) &&
+		!at.Before(contest.FreezeAt())
+}
+
+// hidesSubmit reports whether the result of the contest submission is hidden from the user taking
+// part as entrant: in contests hiding the results, and for the submissions of the other entrants
+// during the scoreboard freeze.
+func hidesSubmit(contest *model.Contest, submit *model.ContestSubmit, uid, entrant string) bool {
+	if middleware.IsAdmin(uid) {
+		return false
+	}
+	return contest.HidesResults(time.Now()) ||
+		frozenSubmit(contest, submit.Upsolve, submit.CreatedAT) && (entrant == "" || submit.UID != entrant)
+}
+
+// hideResult clears the result of a contest submission.
+func hideResult(submit *model.ContestSubmit) {
+	submit.Result = common.Submitted
+	submit.Score, submit.RunTime, submit.Memory = 0, 0, 0
+}
+
+// endedContest loads the contest given by the cid query param, it must have ended.
+func endedContest(ctx *gin.Context) (*db.SqlExec, *model.Contest, error) {
+	cid := ctx.Query("cid")
+	if cid == "" {
+		return nil, nil, errors.Errorf("invalid param cid: %v", cid)
+	}
+	sqlExec, err := db.GetSqlExec(ctx.Request.Context(), "problem")
+	if err != nil {
+		return nil, nil, err
+	}
+	contest, err := model.GetOneContest(sqlExec, map[string]interface{}{
+		"id": cid,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if time.Now().Before(contest.EndAt) {
+		return nil, nil, errors.Errorf("contest %d has not ended.", contest.ID)
+	}
+	return sqlExec, contest, nil
+}
+
+// unfreezeContest reveals the frozen scoreboard all at once.
+func unfreezeContest(ctx *gin.Context) gin.HandlerFunc {
+	sqlExec, contest, err := endedContest(ctx)
+	if err != nil {
+		return reply.Err(err)
+	}
+	// end_at is set again, databases created before the schema fix update it on every change.
+	_, err = sqlExec.Exec("UPDATE contest SET unfrozen = 1, end_at = end_at WHERE id = ?", contest.ID)
+	if err != nil {
+		return reply.Err(errors.Wrap(err, "db error."))
+	}
+	stream.ContestChanged(contest.ID)
+	return reply.Success(http.StatusOK, nil)
+}
+
+// resolveContest returns the frozen standings and the reveal sequence leading to the final ones,
+// for a resolver ceremony. The scoreboard stays frozen until it is unfrozen.
+func resolveContest(ctx *gin.Context) gin.HandlerFunc {
+	sqlExec, contest, err := endedContest(ctx)
+	if err != nil {
+		return reply.Err(err)
+	}
+	names, err := entrantNames(ctx, sqlExec)
+	if err != nil {
+		return reply.Err(err)
+	}
+	frozen, err := scoreboard.LoadICPC(sqlExec, *contest, names, true)
+	if err != nil {
+		return reply.Err(err)
+	}
+	final, err := scoreboard.LoadICPC(sqlExec, *contest, names, false)
+	if err != nil {
+		return reply.Err(err)
+	}
+	return reply.Success(http.StatusOK, map[string]interface{}{
+		"frozen": frozen,
+		"steps":  scoreboard.Resolve(frozen, final),
+		"final":  final,
+	})
+}
+
+// checkProblemVisible rejects the problems of the contests not ended the user may not read.
+func checkProblemVisible(ctx *gin.Context, sqlExec *db.SqlExec, problem *model.Problem) gin.HandlerFunc {
+	ok, err := problemVisible(sqlExec, problem, middleware.GetCurrentID(ctx))
+	if err != nil {
+		return reply.Err(err)
+	}
+	if !ok {
+		return replyCode(http.StatusForbidden, CodeNotParticipant,
+			errors.Errorf("problem %d belongs to a contest you do not participate in.", problem.ID))
+	}
+	return nil
+}
+
+// registerContest registers the user, or the team of the user given by team_id, to the contest
+// until its end: freely to public contests, with the invitation code to private ones and with the
+// password to password contests. An account takes part once, alone or in one team.
+func registerContest(ctx *gin.Context) gin.HandlerFunc {
+	var (
+		request = struct {
+			CID        int64  `json:"cid"`
+			TeamID     int64  `json:"team_id"`
+			Password   string `json:"password"`
+			InviteCode string `json:"invite_code"`
+		}{}
+	)
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		return reply.ErrorWithMessage(err, "invalid param")
+	}
+	sqlExec, err := db.GetSqlExec(ctx.Request.Context(), "problem")
+	if err != nil {
+		return reply.Err(err)
+	}
+	contests, err := model.GetContest(sqlExec, map[string]interface{}{
+		"id": request.CID,
+	})
+	if err != nil {
+		return reply.Err(err)
+	}
+	if len(contests) == 0 {
+		return replyCode(http.StatusNotFound, CodeContestNotFound,
+			errors.Errorf("contest %d not found.", request.CID))
+	}
+	contest := &contests[0]
+	if !time.Now().Before(contest.EndAt) {
+		return reply.Err(errors.Errorf("contest %d has ended.", request.CID))
+	}
+	switch contest.Encrypt {
+	case model.PrivateContest:
+		if contest.InviteCode == "" || request.InviteCode != contest.InviteCode {
+			return replyCode(http.StatusForbidden, CodeNotParticipant, errors.Errorf("invalid invitation code."))
+		}
+	case model.PasswordContest:
+		if !contest.CheckPassword(request.Password) {
+			return replyCode(http.StatusForbidden, CodeNotParticipant, errors.Errorf("invalid password."))
+		}
+	}
+	var (
+		uid     = middleware.GetCurrentID(ctx)
+		entrant = uid
+		members = []string{uid}
+	)
+	if request.TeamID != 0 {
+		teamMembers, err := model.GetTeamMembers(sqlExec, map[string]interface{}{
+			"team_id": request.TeamID,
+		})
+		if err != nil {
+			return reply.Err(err)
+		}
+		members = members[:0]
+		for _, m := range teamMembers {
+			if m.Accepted {
+				members = append(members, m.UID)
+			}
+		}
+		if !containsString(members, uid) {
+			return replyCode(http.StatusForbidden, CodeNotParticipant,
+				errors.Errorf("you are not a member of team %d.", request.TeamID))
+		}
+		entrant = model.TeamEntrant(request.TeamID)
+	}
+	if err := checkEntrants(sqlExec, contest.ID, entrant, members); err != nil {
+		return reply.Err(err)
+	}
+	if err := model.AddContestParticipants(sqlExec, contest.ID, []string{entrant}); err != nil {
+		return reply.Err(err)
+	}
+	return reply.Success(http.StatusOK, nil)
+}
+
+// checkEntrants rejects the accounts already taking part in the contest other than as entrant.
+func checkEntrants(sqlExec *db.SqlExec, cid int64, entrant string, uids []string) error {
+	for _, uid := range uids {
+		other, ok, err := contestEntrant(sqlExec, cid, uid)
+		if err != nil {
+			return err
+		}
+		if ok && other != entrant {
+			return errors.Errorf("%s already takes part in contest %d as %s.", uid, cid, other)
+		}
+	}
+	return nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// updateParticipants adds the users to the participants of the contest, or removes them.
+func updateParticipants(ctx *gin.Context) gin.HandlerFunc {
+	var (
+		request = struct {
+			CID    int64    `json:"cid"`
+			UIDs   []string `json:"uids"`
+			Remove bool     `json:"remove"`
+		}{}
+	)
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		return reply.ErrorWithMessage(err, "invalid param")
+	}
+	sqlExec, err := db.GetSqlExec(ctx.Request.Context(), "problem")
+	if err != nil {
+		return reply.Err(err)
+	}
+	if request.Remove {
+		if _, err := model.DeleteContestParticipants(sqlExec, request.CID, request.UIDs); err != nil {
+			return reply.Err(err)
+		}
+	} else if err := model.AddContestParticipants(sqlExec, request.CID, request.UIDs); err != nil {
+		return reply.Err(err)
+	}
+	return reply.Success(http.StatusOK, nil)
+}
+
+func getParticipants(ctx *gin.Context) gin.HandlerFunc {
+	cid := ctx.Query("cid")
+	if cid == "" {
+		return reply.Err(errors.Errorf("invalid param cid: %v", cid))
+	}
+	sqlExec, err := db.GetSqlExec(ctx.Request.Context(), "problem")
+	if err != nil {
+		return reply.Err(err)
+	}
+	participants, err := model.GetContestParticipants(sqlExec, map[string]interface{}{
+		"cid": cid,
+	})
+	if err != nil {
+		return reply.Err(err)
+	}
+	return reply.Success(http.StatusOK, map[string]interface{}{
+		"list":  participants,
+		"total": len(participants),
+	})
+}
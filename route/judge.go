@@ -1,10 +1,7 @@
 package route
 
 import (
-	"bytes"
-	"crypto/md5"
 	"fmt"
-	"io/ioutil"
 	"log"
 	"net/http"
 	"os"
@@ -21,9 +18,11 @@ import (
 	"github.com/pkg/errors"
 
 	"online_judge/JudgeServer/common"
+	"online_judge/JudgeServer/driver"
 	"online_judge/JudgeServer/middleware"
 	"online_judge/JudgeServer/model"
 	"online_judge/JudgeServer/sandbox"
+	"online_judge/JudgeServer/stream"
 	"online_judge/JudgeServer/utils"
 )
 
@@ -41,6 +40,18 @@ func JudgeRouteModule() router.ModuleRoute {
 			reply.Wrap(judgeProblem),
 			middleware.VerifyLogin,
 		),
+		router.NewRouter(
+			"/v1/submission/submit_output",
+			http.MethodPost,
+			reply.Wrap(judgeOutputOnly),
+			middleware.VerifyLogin,
+		),
+		router.NewRouter(
+			"/v1/submission/output",
+			http.MethodGet,
+			reply.Wrap(getSubmitOutputs),
+			middleware.VerifyLogin,
+		),
 		router.NewRouter(
 			"/v1/problem/add_data",
 			http.MethodPost,
@@ -63,11 +74,19 @@ func JudgeRouteModule() router.ModuleRoute {
 			"/v1/problem/detail",
 			http.MethodGet,
 			reply.Wrap(getProblem),
+			middleware.OptionalLogin,
+		),
+		router.NewRouter(
+			"/v1/problem/header",
+			http.MethodGet,
+			reply.Wrap(getProblemHeader),
+			middleware.OptionalLogin,
 		),
 		router.NewRouter(
 			"/v1/problem/list",
 			http.MethodGet,
 			reply.Wrap(getProblems),
+			middleware.OptionalLogin,
 		),
 		router.NewRouter(
 			"/v1/submit/list",
@@ -90,6 +109,34 @@ func JudgeRouteModule() router.ModuleRoute {
 			"/v1/contest",
 			http.MethodGet,
 			reply.Wrap(getContest),
+			middleware.OptionalLogin,
+		),
+		router.NewRouter("/v1/contest/virtual/start",
+			http.MethodPost,
+			reply.Wrap(startVirtual),
+			middleware.VerifyLogin,
+		),
+		router.NewRouter("/v1/contest/virtual/rank",
+			http.MethodGet,
+			reply.Wrap(virtualRank),
+			middleware.VerifyLogin,
+		),
+		router.NewRouter("/v1/contest/register",
+			http.MethodPost,
+			reply.Wrap(registerContest),
+			middleware.VerifyLogin,
+		),
+		router.NewRouter("/v1/contest/participants",
+			http.MethodPost,
+			reply.Wrap(updateParticipants),
+			middleware.VerifyAdmin,
+			middleware.VerifyLogin,
+		),
+		router.NewRouter("/v1/contest/participants/list",
+			http.MethodGet,
+			reply.Wrap(getParticipants),
+			middleware.VerifyAdmin,
+			middleware.VerifyLogin,
 		),
 		router.NewRouter(
 			"/v1/contest/submit",
@@ -101,10 +148,79 @@ func JudgeRouteModule() router.ModuleRoute {
 			"/v1/contest/submission",
 			http.MethodGet,
 			reply.Wrap(contestStatus),
+			middleware.OptionalLogin,
 		),
 		router.NewRouter("/v1/contest/rank",
 			http.MethodGet,
 			reply.Wrap(contestRank),
+			middleware.OptionalLogin,
+		),
+		router.NewRouter("/v1/contest/clarification/ask",
+			http.MethodPost,
+			reply.Wrap(askClarification),
+			middleware.VerifyLogin,
+		),
+		router.NewRouter("/v1/contest/clarification/answer",
+			http.MethodPost,
+			reply.Wrap(answerClarification),
+			middleware.VerifyAdmin,
+			middleware.VerifyLogin,
+		),
+		router.NewRouter("/v1/contest/clarification/list",
+			http.MethodGet,
+			reply.Wrap(listClarifications),
+			middleware.VerifyLogin,
+		),
+		router.NewRouter("/v1/contest/clarification/read",
+			http.MethodPost,
+			reply.Wrap(readClarifications),
+			middleware.VerifyLogin,
+		),
+		router.NewRouter("/v1/contest/announcement",
+			http.MethodPost,
+			reply.Wrap(announce),
+			middleware.VerifyAdmin,
+			middleware.VerifyLogin,
+		),
+		router.NewRouter("/v1/contest/rank/stream",
+			http.MethodGet,
+			reply.Wrap(rankStream),
+			middleware.OptionalLogin,
+		),
+		router.NewRouter("/v1/submission/stream",
+			http.MethodGet,
+			reply.Wrap(submissionStream),
+			middleware.OptionalLogin,
+		),
+		router.NewRouter("/v1/contest/unfreeze",
+			http.MethodPost,
+			reply.Wrap(unfreezeContest),
+			middleware.VerifyAdmin,
+			middleware.VerifyLogin,
+		),
+		router.NewRouter("/v1/contest/resolve",
+			http.MethodGet,
+			reply.Wrap(resolveContest),
+			middleware.VerifyAdmin,
+			middleware.VerifyLogin,
+		),
+		router.NewRouter("/v1/contest/system_test",
+			http.MethodPost,
+			reply.Wrap(systemTest),
+			middleware.VerifyAdmin,
+			middleware.VerifyLogin,
+		),
+		router.NewRouter("/v1/rejudge",
+			http.MethodPost,
+			reply.Wrap(rejudge),
+			middleware.VerifyAdmin,
+			middleware.VerifyLogin,
+		),
+		router.NewRouter("/v1/rejudge/job",
+			http.MethodGet,
+			reply.Wrap(rejudgeJob),
+			middleware.VerifyAdmin,
+			middleware.VerifyLogin,
 		),
 		router.NewRouter("/v1/contest/list",
 			http.MethodGet,
@@ -117,19 +233,8 @@ func JudgeRouteModule() router.ModuleRoute {
 	}
 }
 
+// contestRank returns the standings of the contest, ordered by rank.
 func contestRank(ctx *gin.Context) gin.HandlerFunc {
-	type (
-		problem = struct {
-			PID      int       `json:"pid"`
-			Failed   int       `json:"failed"`
-			CreateAT time.Time `json:"create_at"`
-		}
-		user = struct {
-			ID       string    `json:"id"`
-			Name     string    `json:"name"`
-			Problems []problem `json:"problems"`
-		}
-	)
 	cid := ctx.Query("cid")
 	if cid == "" {
 		return reply.Success(200, nil)
@@ -138,73 +243,19 @@ func contestRank(ctx *gin.Context) gin.HandlerFunc {
 	if err != nil {
 		return reply.Err(err)
 	}
-	allSubmit, err := model.GetContestSubmit(sqlExec, map[string]interface{}{
-		"cid": cid,
+	contest, err := model.GetOneContest(sqlExec, map[string]interface{}{
+		"id": cid,
 	})
 	if err != nil {
 		return reply.Err(err)
 	}
-	allAccount, err := model.GetAccounts(ctx, nil)
+	standings, err := contestStandings(ctx, sqlExec, contest, middleware.GetCurrentID(ctx))
 	if err != nil {
 		return reply.Err(err)
 	}
-	acs := make(map[string]string)
-	for _, ac := range allAccount {
-		acs[ac.ID] = ac.Name
-	}
-	Rank := make(map[string]*user)
-	for _, sb := range allSubmit {
-		if _, ok := Rank[sb.UID]; !ok {
-			Rank[sb.UID] = &user{
-				sb.UID,
-				acs[sb.UID],
-				make([]problem, 0),
-			}
-		}
-	}
-	var found bool
-	var pro *problem
-	for _, sb := range allSubmit {
-		pro = nil
-		found = false
-		if Rank[sb.UID].ID == sb.UID {
-			found = true
-		}
-		if !found {
-			continue
-		}
-		for i := 0; i < len(Rank[sb.UID].Problems); i++ {
-			if Rank[sb.UID].Problems[i].PID == sb.PID {
-				pro = &Rank[sb.UID].Problems[i]
-			}
-		}
-		if pro == nil {
-			failed := 0
-			if sb.Result != common.Accept {
-				failed = -1
-			}
-			Rank[sb.UID].Problems = append(Rank[sb.UID].Problems, problem{
-				sb.PID,
-				failed,
-				sb.CreatedAT,
-			})
-
-		} else {
-			if pro.CreateAT.After(sb.CreatedAT) {
-				pro.CreateAT = sb.CreatedAT
-			}
-			if pro.Failed < 0 {
-				if sb.Result != common.Accept {
-					pro.Failed--
-				} else {
-					pro.Failed = -pro.Failed
-				}
-			}
-		}
-	}
-	return func(context *gin.Context) {
-		context.JSON(200, Rank)
-	}
+	return reply.Success(http.StatusOK, map[string]interface{}{
+		"list": standings,
+	})
 }
 
 func judgeContestSubmit(ctx *gin.Context) gin.HandlerFunc {
@@ -219,46 +270,91 @@ func judgeContestSubmit(ctx *gin.Context) gin.HandlerFunc {
 		return reply.ErrorWithMessage(err, "invalid param")
 	}
 	fmt.Printf("%+v\n", request)
-	sandBox, err := sandbox.NewSandBox(request.Request)
+	submittedAt := time.Now()
+	sqlExec, err := db.GetSqlExec(ctx, "problem")
+	if err != nil {
+		return reply.Err(err)
+	}
+	contest, upsolve, rejected := checkContestSubmit(ctx, sqlExec, request.CID, request.ProblemID)
+	if rejected != nil {
+		return rejected
+	}
+	request.Pretest = contest.Pretest && !upsolve
+	uid := middleware.GetCurrentID(ctx)
+	entrant, _, err := contestEntrant(sqlExec, contest.ID, uid)
 	if err != nil {
 		return reply.Err(err)
 	}
-	res, err := sandBox.Run()
+	virtual, err := inVirtual(sqlExec, contest, uid)
 	if err != nil {
 		return reply.Err(err)
 	}
 
-	sqlExec, err := db.GetSqlExec(ctx, "problem")
+	judger, err := sandbox.NewJudger(request.Request)
 	if err != nil {
 		return reply.Err(err)
 	}
+	res, err := sandbox.Judge(judger, sandbox.HighPriority)
+	if err != nil {
+		return reply.Err(err)
+	}
+
 	// log.Println(middleware.GetCurrentID(ctx))
 	_, err = model.AddContestSubmit(sqlExec, model.ContestSubmit{
-		CID: request.CID,
+		CID:     request.CID,
+		Upsolve: upsolve,
+		Virtual: virtual,
 		Submit: model.Submit{
-			PID:      request.ProblemID,
-			UID:      middleware.GetCurrentID(ctx),
-			SubmitID: request.ID,
-			Code:     request.Code,
-			Language: request.Language,
-			Result:   res.Status,
-			RunTime:  res.Time,
-			Memory:   res.Memory,
+			PID:       request.ProblemID,
+			UID:       entrant,
+			Author:    uid,
+			SubmitID:  request.ID,
+			Code:      request.Code,
+			Language:  request.Language,
+			Result:    res.Status,
+			RunTime:   res.Time,
+			Memory:    res.Memory,
+			Score:     res.Score,
+			CreatedAT: submittedAt,
 		},
 	})
 	if err != nil {
 		return reply.Err(err)
 	}
+	if err := model.AddSubmitOutputs(sqlExec, res.KeptOutputs()); err != nil {
+		log.Print(err)
+	}
+	if scores := res.RawScores(); len(scores) != 0 {
+		if err := model.SaveSubmitScores(sqlExec, request.ID, scores); err != nil {
+			log.Print(err)
+		}
+	}
+	if len(res.Subtasks) != 0 {
+		if err := model.SaveSubmitSubtasks(sqlExec, request.ID, res.Subtasks); err != nil {
+			log.Print(err)
+		}
+	}
+	publishResult(request.ID, res, contest.HidesResults(time.Now()) || frozenSubmit(contest, upsolve, time.Now()))
+	stream.ContestChanged(request.CID)
+	if hidesResults(contest, uid) {
+		return reply.Success(200, map[string]interface{}{
+			"data": map[string]string{
+				"result": common.Submitted,
+			},
+		})
+	}
 
 	return reply.Success(200, map[string]interface{}{
 		"data": struct {
-			Result string `json:"result"`
-			Time   int64  `json:"time"`
-			Memory int64  `json:"memory"`
+			Result string           `json:"result"`
+			Time   int64            `json:"time"`
+			Memory int64            `json:"memory"`
+			Cases  []sandbox.Result `json:"cases"`
 		}{
 			res.Status,
 			res.Time,
 			res.Memory,
+			res.Cases,
 		},
 	})
 }
@@ -271,16 +367,25 @@ func judgeProblem(ctx *gin.Context) gin.HandlerFunc {
 	}
 
 	fmt.Println("request: ", request)
-
-	sandBox, err := sandbox.NewSandBox(request)
+	sqlExec, err := db.GetSqlExec(ctx.Request.Context(), "problem")
 	if err != nil {
 		return reply.Err(err)
 	}
-	res, err := sandBox.Run()
+	problem, err := model.GetOneProblem(sqlExec, map[string]interface{}{
+		"id": request.ProblemID,
+	})
 	if err != nil {
 		return reply.Err(err)
 	}
-	sqlExec, err := db.GetSqlExec(ctx.Request.Context(), "problem")
+	if rejected := checkProblemVisible(ctx, sqlExec, problem); rejected != nil {
+		return rejected
+	}
+
+	judger, err := sandbox.NewJudger(request)
+	if err != nil {
+		return reply.Err(err)
+	}
+	res, err := sandbox.Judge(judger, sandbox.HighPriority)
 	if err != nil {
 		return reply.Err(err)
 	}
@@ -294,21 +399,38 @@ func judgeProblem(ctx *gin.Context) gin.HandlerFunc {
 		Result:   res.Status,
 		RunTime:  res.Time,
 		Memory:   res.Memory,
+		Score:    res.Score,
 	})
 	if err != nil {
 		return reply.Err(err)
 	}
 	log.Printf("%d rows affected.", rowsAffected)
+	if err := model.AddSubmitOutputs(sqlExec, res.KeptOutputs()); err != nil {
+		log.Print(err)
+	}
+	if scores := res.RawScores(); len(scores) != 0 {
+		if err := model.SaveSubmitScores(sqlExec, request.ID, scores); err != nil {
+			log.Print(err)
+		}
+	}
+	if len(res.Subtasks) != 0 {
+		if err := model.SaveSubmitSubtasks(sqlExec, request.ID, res.Subtasks); err != nil {
+			log.Print(err)
+		}
+	}
+	publishResult(request.ID, res, false)
 
 	return reply.Success(http.StatusOK, map[string]interface{}{
 		"data": struct {
-			Result string `json:"result"`
-			Time   int64  `json:"tint"`
-			Memory int64  `json:"memory"`
+			Result string           `json:"result"`
+			Time   int64            `json:"tint"`
+			Memory int64            `json:"memory"`
+			Cases  []sandbox.Result `json:"cases"`
 		}{
 			res.Status,
 			res.Time,
 			res.Memory,
+			res.Cases,
 		},
 	})
 }
@@ -344,12 +466,17 @@ func updateProblem(ctx *gin.Context) gin.HandlerFunc {
 			OutputDes   string `json:"output_des"`
 			Input       string `json:"case_data_input"`
 			Output      string `json:"case_data_output"`
+			FileInput   string `json:"file_input"`
+			FileOutput  string `json:"file_output"`
 		}{}
 	)
 	err := ctx.ShouldBindJSON(&problem)
 	if err != nil {
 		return reply.ErrorWithMessage(err, "invalid param")
 	}
+	if err := model.ValidFileIO(problem.FileInput, problem.FileOutput); err != nil {
+		return reply.ErrorWithMessage(err, "invalid param")
+	}
 	sqlExec, err := db.GetSqlExec(ctx.Request.Context(), "problem")
 	if err != nil {
 		return reply.Err(err)
@@ -363,6 +490,8 @@ func updateProblem(ctx *gin.Context) gin.HandlerFunc {
 		"output_des":       problem.OutputDes,
 		"case_data_input":  problem.Input,
 		"case_data_output": problem.Output,
+		"file_input":       problem.FileInput,
+		"file_output":      problem.FileOutput,
 	})
 	if err != nil {
 		return reply.Err(err)
@@ -384,9 +513,76 @@ func getProblem(ctx *gin.Context) gin.HandlerFunc {
 	if err != nil {
 		return reply.Err(err)
 	}
+	if rejected := checkProblemVisible(ctx, sqlExec, problem); rejected != nil {
+		return rejected
+	}
+	samples, err := sandbox.Samples(sqlExec, *problem)
+	if err != nil {
+		return reply.Err(err)
+	}
+	headers, err := model.GetProblemPrograms(sqlExec, map[string]interface{}{
+		"pid":  pid,
+		"type": model.HeaderProgram,
+	})
+	if err != nil {
+		return reply.Err(err)
+	}
+	type header struct {
+		Name     string `json:"name"`
+		Language string `json:"language"`
+	}
+	headerList := make([]header, 0, len(headers))
+	for _, h := range headers {
+		headerList = append(headerList, header{h.Name, h.Language})
+	}
+	var starters map[string]string
+	if problem.Signature != "" {
+		sig, err := driver.ParseSignature(problem.Signature)
+		if err != nil {
+			return reply.Err(err)
+		}
+		starters = driver.Starters(sig)
+	}
 	return reply.Success(200, map[string]interface{}{
-		"problem": problem,
+		"problem":  problem,
+		"samples":  samples,
+		"headers":  headerList,
+		"starters": starters,
+	})
+}
+
+// getProblemHeader downloads a grader header of a function problem.
+func getProblemHeader(ctx *gin.Context) gin.HandlerFunc {
+	pid := ctx.Query("pid")
+	name := ctx.Query("name")
+	if pid == "" || name == "" {
+		return reply.Err(errors.Errorf("invalid param pid: %v, name: %v", pid, name))
+	}
+	sqlExec, err := db.GetSqlExec(ctx.Request.Context(), "problem")
+	if err != nil {
+		return reply.Err(err)
+	}
+	problem, err := model.GetOneProblem(sqlExec, map[string]interface{}{
+		"id": pid,
 	})
+	if err != nil {
+		return reply.Err(err)
+	}
+	if rejected := checkProblemVisible(ctx, sqlExec, problem); rejected != nil {
+		return rejected
+	}
+	header, err := model.GetOneProblemProgram(sqlExec, map[string]interface{}{
+		"pid":  pid,
+		"name": name,
+		"type": model.HeaderProgram,
+	})
+	if err != nil {
+		return reply.Err(err)
+	}
+	return func(context *gin.Context) {
+		context.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", header.Name))
+		context.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(header.Code))
+	}
 }
 
 func getProblems(ctx *gin.Context) gin.HandlerFunc {
@@ -398,8 +594,19 @@ func getProblems(ctx *gin.Context) gin.HandlerFunc {
 	if err != nil {
 		return reply.Err(err)
 	}
+	uid := middleware.GetCurrentID(ctx)
+	hidden, err := hiddenProblems(sqlExec, uid)
+	if err != nil {
+		return reply.Err(err)
+	}
+	list := make([]model.Problem, 0, len(problemList))
+	for _, problem := range problemList {
+		if !hidden[problem.ID] || problem.Author == uid {
+			list = append(list, problem)
+		}
+	}
 	return reply.Success(200, map[string]interface{}{
-		"list": problemList,
+		"list": list,
 	})
 }
 
@@ -432,6 +639,28 @@ func contestStatus(ctx *gin.Context) gin.HandlerFunc {
 	if err != nil {
 		return reply.Err(err)
 	}
+	var (
+		uid      = middleware.GetCurrentID(ctx)
+		contests = make(map[int64]*model.Contest)
+		entrants = make(map[int64]string)
+	)
+	for i := range submits {
+		contest, ok := contests[submits[i].CID]
+		if !ok {
+			if contest, err = model.GetOneContest(sqlExec, map[string]interface{}{
+				"id": submits[i].CID,
+			}); err != nil {
+				return reply.Err(err)
+			}
+			contests[submits[i].CID] = contest
+			if entrants[contest.ID], _, err = contestEntrant(sqlExec, contest.ID, uid); err != nil {
+				return reply.Err(err)
+			}
+		}
+		if hidesSubmit(contest, &submits[i], uid, entrants[contest.ID]) {
+			hideResult(&submits[i])
+		}
+	}
 	return reply.Success(200, map[string]interface{}{
 		"list":  submits,
 		"total": len(submits),
@@ -465,6 +694,7 @@ func addProblemData(ctx *gin.Context) gin.HandlerFunc {
 				PID:        pidInt,
 				InputFile:  path.Join(fileDir, FileNameNotExt(file.Filename)+".in"),
 				OutputFile: path.Join(fileDir, FileNameNotExt(file.Filename)+".out"),
+				IsSample:   ctx.Query("sample") == "true",
 			})
 		}
 	}
@@ -478,13 +708,17 @@ func addProblemData(ctx *gin.Context) gin.HandlerFunc {
 	if err != nil {
 		return reply.Err(err)
 	}
+	normalize := ctx.Query("normalize") == "true"
+	lints := make([]utils.LintReport, 0)
 	for i := 0; i < len(proDatas); i++ {
-		data, err := ioutil.ReadFile(proDatas[i].OutputFile)
+		reports, err := lintProblemData(proDatas[i], normalize)
 		if err != nil {
-			continue
+			return reply.Err(err)
+		}
+		lints = append(lints, reports...)
+		if err := proDatas[i].CalculMD5(); err != nil {
+			return reply.Err(err)
 		}
-		proDatas[i].MD5 = utils.CovertMD5(md5.Sum(data))
-		proDatas[i].MD5TrimSpace = utils.CovertMD5(md5.Sum(bytes.TrimSpace(data)))
 	}
 	lastId, err := model.AddProblemDatas(sqlExec, proDatas)
 	if err != nil {
@@ -492,6 +726,7 @@ func addProblemData(ctx *gin.Context) gin.HandlerFunc {
 	}
 	return reply.Success(http.StatusOK, map[string]interface{}{
 		"data": lastId,
+		"lint": lints,
 	})
 }
 
@@ -539,6 +774,11 @@ func createContest(ctx *gin.Context) gin.HandlerFunc {
 		c = struct {
 			Title      string `json:"title"`
 			Encrypt    int    `json:"encrypt"`
+			Pretest    bool   `json:"pretest"`
+			Mode       string `json:"mode"`
+			Freeze     int    `json:"freeze_minutes"`
+			Password   string `json:"password"`
+			InviteCode string `json:"invite_code"`
 			StartAt    int64  `json:"start"`
 			EndAt      int64  `json:"end"`
 			ProblemIDs []int  `json:"list"`
@@ -550,10 +790,15 @@ func createContest(ctx *gin.Context) gin.HandlerFunc {
 	}
 	fmt.Println(c)
 	cid, err := model.AddContest(ctx, model.Contest{
-		Title:   c.Title,
-		Encrypt: c.Encrypt,
-		StartAt: time.Unix(c.StartAt/1000, c.StartAt%1000),
-		EndAt:   time.Unix(c.EndAt/1000, c.StartAt%1000),
+		Title:         c.Title,
+		Encrypt:       c.Encrypt,
+		Pretest:       c.Pretest,
+		Mode:          c.Mode,
+		FreezeMinutes: c.Freeze,
+		Password:      c.Password,
+		InviteCode:    c.InviteCode,
+		StartAt:       time.Unix(c.StartAt/1000, c.StartAt%1000),
+		EndAt:         time.Unix(c.EndAt/1000, c.StartAt%1000),
 	})
 	if err != nil {
 		return reply.Err(err)
@@ -606,6 +851,23 @@ func getContest(ctx *gin.Context) gin.HandlerFunc {
 	if err != nil {
 		return reply.Err(err)
 	}
+	uid := middleware.GetCurrentID(ctx)
+	_, registered, err := contestEntrant(sqlExec, contest.ID, uid)
+	if err != nil {
+		return reply.Err(err)
+	}
+	visible, err := canViewProblems(sqlExec, contest, uid)
+	if err != nil {
+		return reply.Err(err)
+	}
+	if !visible {
+		return reply.Success(200, map[string]interface{}{
+			"contest":    contest,
+			"registered": registered,
+			"list":       []model.ContestProblem{},
+			"total":      0,
+		})
+	}
 	list, err := model.GetContestProblems(sqlExec, map[string]interface{}{
 		"cid": cid,
 	})
@@ -630,9 +892,10 @@ func getContest(ctx *gin.Context) gin.HandlerFunc {
 		}
 	}
 	return reply.Success(200, map[string]interface{}{
-		"contest": contest,
-		"list":    list,
-		"total":   len(list),
+		"contest":    contest,
+		"registered": registered,
+		"list":       list,
+		"total":      len(list),
 	})
 }
 func FileNameNotExt(name string) string {
@@ -643,3 +906,152 @@ func FileNameNotExt(name string) string {
 	}
 	return name
 }
+
+// getSubmitOutputs returns the kept outputs of the failed cases of a submission, only the problem
+// author and admins can see them.
+func getSubmitOutputs(ctx *gin.Context) gin.HandlerFunc {
+	sid := ctx.Query("sid")
+	if sid == "" {
+		return reply.Err(errors.Errorf("invalid param sid: %v", sid))
+	}
+	sqlExec, err := db.GetSqlExec(ctx.Request.Context(), "problem")
+	if err != nil {
+		return reply.Err(err)
+	}
+	var pid int
+	if submit, err := model.GetOneSubmit(ctx, map[string]interface{}{"submit_id": sid}); err == nil {
+		pid = submit.PID
+	} else {
+		css, err := model.GetContestSubmit(sqlExec, map[string]interface{}{"submit_id": sid})
+		if err != nil {
+			return reply.Err(err)
+		}
+		if len(css) != 1 {
+			return reply.Err(errors.Errorf("submission %s not found.", sid))
+		}
+		pid = css[0].PID
+	}
+	problem, err := model.GetOneProblem(sqlExec, map[string]interface{}{
+		"id": pid,
+	})
+	if err != nil {
+		return reply.Err(err)
+	}
+	uid := middleware.GetCurrentID(ctx)
+	if problem.Author != uid && !middleware.IsAdmin(uid) {
+		return reply.Err(errors.Errorf("permission denied."))
+	}
+	outputs, err := model.GetSubmitOutputs(sqlExec, map[string]interface{}{
+		"submit_id": sid,
+	})
+	if err != nil {
+		return reply.Err(err)
+	}
+	return reply.Success(http.StatusOK, map[string]interface{}{
+		"list":  outputs,
+		"total": len(outputs),
+	})
+}
+
+// systemTest judges the last pretest passed submissions of an ended contest on the full test set.
+func systemTest(ctx *gin.Context) gin.HandlerFunc {
+	cid := ctx.Query("cid")
+	if cid == "" {
+		return reply.ErrorWithMessage(nil, "invalid cid")
+	}
+	sqlExec, err := db.GetSqlExec(ctx, "problem")
+	if err != nil {
+		return reply.Err(err)
+	}
+	contest, err := model.GetOneContest(sqlExec, map[string]interface{}{
+		"id": cid,
+	})
+	if err != nil {
+		return reply.Err(err)
+	}
+	if time.Now().Before(contest.EndAt) {
+		return reply.Err(errors.Errorf("contest is not over."))
+	}
+	changes, err := sandbox.SystemTest(sqlExec, *contest)
+	if err != nil {
+		return reply.Err(err)
+	}
+	return reply.Success(http.StatusOK, map[string]interface{}{
+		"list":  changes,
+		"total": len(changes),
+	})
+}
+
+// rejudge judges again a single submission (sid), all submissions of a problem (pid) or all
+// submissions of a contest (cid), and reports the verdict changes. With dry_run nothing is written.
+// A contest is rejudged in the background, the reply holds the id of the job, see rejudgeJob.
+func rejudge(ctx *gin.Context) gin.HandlerFunc {
+	var (
+		request = struct {
+			SID    string `json:"sid"`
+			PID    int    `json:"pid"`
+			CID    int64  `json:"cid"`
+			DryRun bool   `json:"dry_run"`
+		}{}
+	)
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		return reply.ErrorWithMessage(err, "invalid param")
+	}
+	var filters map[string]interface{}
+	switch {
+	case request.SID != "" && request.PID == 0 && request.CID == 0:
+		filters = map[string]interface{}{"submit_id": request.SID}
+	case request.SID == "" && request.PID != 0 && request.CID == 0:
+		filters = map[string]interface{}{"pid": request.PID}
+	case request.SID == "" && request.PID == 0 && request.CID != 0:
+		filters = map[string]interface{}{"cid": request.CID}
+	default:
+		return reply.Err(errors.Errorf("exactly one of sid, pid and cid is required."))
+	}
+	sqlExec, err := db.GetSqlExec(ctx, "problem")
+	if err != nil {
+		return reply.Err(err)
+	}
+	var submits []model.Submit
+	if request.CID == 0 {
+		if submits, err = model.GetSubmits(ctx, filters); err != nil {
+			return reply.Err(err)
+		}
+	}
+	contestSubmits, err := model.GetContestSubmit(sqlExec, filters)
+	if err != nil {
+		return reply.Err(err)
+	}
+	if len(submits)+len(contestSubmits) == 0 {
+		return reply.Err(errors.Errorf("no submission found."))
+	}
+	if request.CID != 0 {
+		return reply.Success(http.StatusOK, map[string]interface{}{
+			"job_id":   sandbox.StartRejudge(submits, contestSubmits, request.DryRun),
+			"rejudged": len(submits) + len(contestSubmits),
+			"dry_run":  request.DryRun,
+		})
+	}
+	changes, err := sandbox.RejudgeSubmits(sqlExec, submits, contestSubmits, request.DryRun)
+	if err != nil {
+		return reply.Err(err)
+	}
+	return reply.Success(http.StatusOK, map[string]interface{}{
+		"list":     changes,
+		"total":    len(changes),
+		"rejudged": len(submits) + len(contestSubmits),
+		"dry_run":  request.DryRun,
+	})
+}
+
+// rejudgeJob reports the state of a background rejudge, with the verdict changes once finished.
+func rejudgeJob(ctx *gin.Context) gin.HandlerFunc {
+	id := ctx.Query("id")
+	job, ok := sandbox.GetRejudgeJob(id)
+	if !ok {
+		return reply.Err(errors.Errorf("rejudge job %s not found.", id))
+	}
+	return reply.Success(http.StatusOK, map[string]interface{}{
+		"job": job,
+	})
+}
@@ -0,0 +1,226 @@
+package route
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/easyAation/scaffold/db"
+	"github.com/easyAation/scaffold/reply"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+
+	"online_judge/JudgeServer/common"
+	"online_judge/JudgeServer/middleware"
+	"online_judge/JudgeServer/model"
+	"online_judge/JudgeServer/sandbox"
+	"online_judge/JudgeServer/stream"
+	"online_judge/JudgeServer/utils"
+)
+
+// maxAnswerSize is the size limit of one answer file of output-only submissions.
+const maxAnswerSize = 64 << 20
+
+// judgeOutputOnly judges the answer files of an output-only problem. The files are uploaded as
+// `files`, one per test named after the test (1.out for the test 1.in), or as zip archives of them.
+// The submission id is generated by the server and returned with the result.
+func judgeOutputOnly(ctx *gin.Context) gin.HandlerFunc {
+	var (
+		pid         int
+		cid         int64
+		id          = utils.UUID()
+		submittedAt = time.Now()
+	)
+	if _, err := fmt.Sscan(ctx.Query("pid"), &pid); err != nil {
+		return reply.Err(errors.Errorf("invalid param pid: %v", ctx.Query("pid")))
+	}
+	if ctx.Query("cid") != "" {
+		if _, err := fmt.Sscan(ctx.Query("cid"), &cid); err != nil {
+			return reply.Err(errors.Errorf("invalid param cid: %v", ctx.Query("cid")))
+		}
+	}
+	form, err := ctx.MultipartForm()
+	if err != nil {
+		return reply.Err(err)
+	}
+	sqlExec, err := db.GetSqlExec(ctx.Request.Context(), "problem")
+	if err != nil {
+		return reply.Err(err)
+	}
+	problem, err := model.GetOneProblem(sqlExec, map[string]interface{}{
+		"id": pid,
+	})
+	if err != nil {
+		return reply.Err(err)
+	}
+	if problem.Type != model.OutputOnlyProblem {
+		return reply.Err(errors.Errorf("problem %d is not output-only.", pid))
+	}
+	var (
+		contest *model.Contest
+		upsolve bool
+	)
+	if cid != 0 {
+		var rejected gin.HandlerFunc
+		if contest, upsolve, rejected = checkContestSubmit(ctx, sqlExec, cid, pid); rejected != nil {
+			return rejected
+		}
+	} else if rejected := checkProblemVisible(ctx, sqlExec, problem); rejected != nil {
+		return rejected
+	}
+
+	dir := sandbox.AnswerDir(id)
+	if err := os.RemoveAll(dir); err != nil {
+		return reply.Err(errors.WithStack(err))
+	}
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return reply.Err(errors.WithStack(err))
+	}
+	outputs := make(map[string]string)
+	for _, file := range form.File["files"] {
+		if err := saveAnswers(file, dir, outputs); err != nil {
+			return reply.ErrorWithMessage(err, "invalid answer file")
+		}
+	}
+	res, err := sandbox.JudgeOutputs(sqlExec, *problem, id, outputs)
+	if err != nil {
+		return reply.Err(err)
+	}
+
+	names := make([]string, 0, len(outputs))
+	for name := range outputs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	submit := model.Submit{
+		PID:       pid,
+		UID:       middleware.GetCurrentID(ctx),
+		Author:    middleware.GetCurrentID(ctx),
+		SubmitID:  id,
+		Code:      strings.Join(names, "\n"),
+		Language:  common.OutputLanguage,
+		Result:    res.Status,
+		Score:     res.Score,
+		CreatedAT: submittedAt,
+	}
+	if submit.Code == "" {
+		submit.Code = "-"
+	}
+	if cid != 0 {
+		if submit.UID, _, err = contestEntrant(sqlExec, cid, submit.Author); err != nil {
+			return reply.Err(err)
+		}
+		var virtual bool
+		if virtual, err = inVirtual(sqlExec, contest, submit.Author); err != nil {
+			return reply.Err(err)
+		}
+		_, err = model.AddContestSubmit(sqlExec, model.ContestSubmit{
+			CID:     cid,
+			Upsolve: upsolve,
+			Virtual: virtual,
+			Submit:  submit,
+		})
+	} else {
+		_, err = model.AddSubmit(sqlExec, &submit)
+	}
+	if err != nil {
+		return reply.Err(err)
+	}
+	if err := model.AddSubmitOutputs(sqlExec, res.KeptOutputs()); err != nil {
+		log.Print(err)
+	}
+	if contest != nil {
+		publishResult(id, res, contest.HidesResults(time.Now()) || frozenSubmit(contest, upsolve, time.Now()))
+		stream.ContestChanged(cid)
+	} else {
+		publishResult(id, res, false)
+	}
+	if contest != nil && hidesResults(contest, submit.Author) {
+		return reply.Success(http.StatusOK, map[string]interface{}{
+			"data": map[string]string{
+				"id":     id,
+				"result": common.Submitted,
+			},
+		})
+	}
+
+	return reply.Success(http.StatusOK, map[string]interface{}{
+		"data": struct {
+			ID     string           `json:"id"`
+			Result string           `json:"result"`
+			Score  float64          `json:"score"`
+			Cases  []sandbox.Result `json:"cases"`
+		}{
+			id,
+			res.Status,
+			res.Score,
+			res.Cases,
+		},
+	})
+}
+
+// saveAnswers saves the uploaded answer file, or every file of the zip archive, into dir and
+// records them in outputs by test name.
+func saveAnswers(file *multipart.FileHeader, dir string, outputs map[string]string) error {
+	if strings.ToLower(filepath.Ext(file.Filename)) != ".zip" {
+		f, err := file.Open()
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		defer f.Close()
+		return saveAnswer(f, file.Filename, dir, outputs)
+	}
+	f, err := file.Open()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer f.Close()
+	archive, err := zip.NewReader(f, file.Size)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	for _, entry := range archive.File {
+		if entry.FileInfo().IsDir() {
+			continue
+		}
+		r, err := entry.Open()
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		err = saveAnswer(r, entry.Name, dir, outputs)
+		r.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func saveAnswer(r io.Reader, fileName, dir string, outputs map[string]string) error {
+	name := FileNameNotExt(filepath.Base(fileName))
+	if name == "" {
+		return errors.Errorf("invalid file name %s", fileName)
+	}
+	data, err := ioutil.ReadAll(io.LimitReader(r, maxAnswerSize+1))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if len(data) > maxAnswerSize {
+		return errors.Errorf("%s exceeds %d bytes", fileName, maxAnswerSize)
+	}
+	outputFile := filepath.Join(dir, name+".out")
+	if err := ioutil.WriteFile(outputFile, data, os.ModePerm); err != nil {
+		return errors.WithStack(err)
+	}
+	outputs[name] = outputFile
+	return nil
+}
@@ -0,0 +1,518 @@
+package route
+
+import (
+	"net/http"
+
+	"github.com/easyAation/scaffold/db"
+	"github.com/easyAation/scaffold/reply"
+	"github.com/easyAation/scaffold/router"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+
+	"online_judge/JudgeServer/driver"
+	"online_judge/JudgeServer/middleware"
+	"online_judge/JudgeServer/model"
+	"online_judge/JudgeServer/sandbox"
+	"online_judge/JudgeServer/utils"
+)
+
+// PrepareRouteModule contains the apis used by problem setters to prepare test data.
+func PrepareRouteModule() router.ModuleRoute {
+	routes := []*router.Router{
+		router.NewRouter(
+			"/v1/problem/program/add",
+			http.MethodPost,
+			reply.Wrap(addProblemProgram),
+			middleware.VerifyLogin,
+		),
+		router.NewRouter(
+			"/v1/problem/program/list",
+			http.MethodGet,
+			reply.Wrap(getProblemPrograms),
+			middleware.VerifyLogin,
+		),
+		router.NewRouter(
+			"/v1/problem/script",
+			http.MethodGet,
+			reply.Wrap(getGenScript),
+			middleware.VerifyLogin,
+		),
+		router.NewRouter(
+			"/v1/problem/script/update",
+			http.MethodPost,
+			reply.Wrap(updateGenScript),
+			middleware.VerifyLogin,
+		),
+		router.NewRouter(
+			"/v1/problem/generate",
+			http.MethodPost,
+			reply.Wrap(generateProblemData),
+			middleware.VerifyLogin,
+		),
+		router.NewRouter(
+			"/v1/problem/invariant",
+			http.MethodPost,
+			reply.Wrap(checkInvariants),
+			middleware.VerifyLogin,
+		),
+		router.NewRouter(
+			"/v1/problem/time_limit/suggest",
+			http.MethodPost,
+			reply.Wrap(suggestTimeLimit),
+			middleware.VerifyLogin,
+		),
+		router.NewRouter(
+			"/v1/problem/stress",
+			http.MethodPost,
+			reply.Wrap(stressTest),
+			middleware.VerifyLogin,
+		),
+		router.NewRouter(
+			"/v1/problem/data/lint",
+			http.MethodPost,
+			reply.Wrap(lintProblemDatas),
+			middleware.VerifyLogin,
+		),
+		router.NewRouter(
+			"/v1/problem/data/sample",
+			http.MethodPost,
+			reply.Wrap(setProblemDataFlag("is_sample")),
+			middleware.VerifyLogin,
+		),
+		router.NewRouter(
+			"/v1/problem/data/pretest",
+			http.MethodPost,
+			reply.Wrap(setProblemDataFlag("is_pretest")),
+			middleware.VerifyLogin,
+		),
+		router.NewRouter(
+			"/v1/problem/data/subtask",
+			http.MethodPost,
+			reply.Wrap(setProblemDataSubtask),
+			middleware.VerifyLogin,
+		),
+		router.NewRouter(
+			"/v1/problem/sql/answer",
+			http.MethodPost,
+			reply.Wrap(answerSQL),
+			middleware.VerifyLogin,
+		),
+		router.NewRouter(
+			"/v1/problem/function/signature",
+			http.MethodPost,
+			reply.Wrap(updateSignature),
+			middleware.VerifyLogin,
+		),
+		router.NewRouter(
+			"/v1/problem/function/cases",
+			http.MethodPost,
+			reply.Wrap(updateFunctionCases),
+			middleware.VerifyLogin,
+		),
+	}
+	return router.ModuleRoute{
+		Routers: routes,
+	}
+}
+
+func addProblemProgram(ctx *gin.Context) gin.HandlerFunc {
+	var program model.ProblemProgram
+	if err := ctx.ShouldBindJSON(&program); err != nil {
+		return reply.ErrorWithMessage(err, "invalid param")
+	}
+	sqlExec, err := db.GetSqlExec(ctx.Request.Context(), "problem")
+	if err != nil {
+		return reply.Err(err)
+	}
+	if _, rejected := ownProblem(ctx, sqlExec, program.PID); rejected != nil {
+		return rejected
+	}
+	if _, err := model.AddProblemProgram(sqlExec, program); err != nil {
+		return reply.Err(err)
+	}
+	return reply.Success(http.StatusOK, nil)
+}
+
+func getProblemPrograms(ctx *gin.Context) gin.HandlerFunc {
+	pid := ctx.Query("pid")
+	if pid == "" {
+		return reply.Err(errors.Errorf("invalid param pid: %v", pid))
+	}
+	sqlExec, err := db.GetSqlExec(ctx.Request.Context(), "problem")
+	if err != nil {
+		return reply.Err(err)
+	}
+	if _, rejected := ownProblem(ctx, sqlExec, pid); rejected != nil {
+		return rejected
+	}
+	programs, err := model.GetProblemPrograms(sqlExec, map[string]interface{}{
+		"pid": pid,
+	})
+	if err != nil {
+		return reply.Err(err)
+	}
+	return reply.Success(http.StatusOK, map[string]interface{}{
+		"list":  programs,
+		"total": len(programs),
+	})
+}
+
+// getGenScript returns the generation script of the problem.
+func getGenScript(ctx *gin.Context) gin.HandlerFunc {
+	sqlExec, err := db.GetSqlExec(ctx.Request.Context(), "problem")
+	if err != nil {
+		return reply.Err(err)
+	}
+	problem, rejected := ownProblem(ctx, sqlExec, ctx.Query("pid"))
+	if rejected != nil {
+		return rejected
+	}
+	return reply.Success(http.StatusOK, map[string]interface{}{
+		"script": problem.GenScript,
+	})
+}
+
+func updateGenScript(ctx *gin.Context) gin.HandlerFunc {
+	var (
+		request = struct {
+			PID    int64  `json:"pid"`
+			Script string `json:"script"`
+		}{}
+	)
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		return reply.ErrorWithMessage(err, "invalid param")
+	}
+	if _, err := sandbox.ParseScript(request.Script); err != nil {
+		return reply.ErrorWithMessage(err, "invalid script")
+	}
+	sqlExec, err := db.GetSqlExec(ctx.Request.Context(), "problem")
+	if err != nil {
+		return reply.Err(err)
+	}
+	if _, rejected := ownProblem(ctx, sqlExec, request.PID); rejected != nil {
+		return rejected
+	}
+	_, err = sqlExec.Exec("UPDATE problem SET gen_script = ? WHERE id = ?", request.Script, request.PID)
+	if err != nil {
+		return reply.Err(errors.Wrap(err, "db error."))
+	}
+	return reply.Success(http.StatusOK, nil)
+}
+
+// ownProblem loads the problem, only its author and admins may prepare it. The returned handler is
+// set when the user is rejected.
+func ownProblem(ctx *gin.Context, sqlExec *db.SqlExec, pid interface{}) (*model.Problem, gin.HandlerFunc) {
+	problem, err := model.GetOneProblem(sqlExec, map[string]interface{}{
+		"id": pid,
+	})
+	if err != nil {
+		return nil, reply.Err(err)
+	}
+	uid := middleware.GetCurrentID(ctx)
+	if problem.Author != uid && !middleware.IsAdmin(uid) {
+		return nil, replyCode(http.StatusForbidden, CodeNotAuthor,
+			errors.Errorf("you are not the author of problem %d.", problem.ID))
+	}
+	return problem, nil
+}
+
+// queryProblem loads the problem given by the pid query param, see ownProblem.
+func queryProblem(ctx *gin.Context) (*db.SqlExec, *model.Problem, gin.HandlerFunc) {
+	pid := ctx.Query("pid")
+	if pid == "" {
+		return nil, nil, reply.Err(errors.Errorf("invalid param pid: %v", pid))
+	}
+	sqlExec, err := db.GetSqlExec(ctx.Request.Context(), "problem")
+	if err != nil {
+		return nil, nil, reply.Err(err)
+	}
+	problem, rejected := ownProblem(ctx, sqlExec, pid)
+	return sqlExec, problem, rejected
+}
+
+// generateProblemData rebuilds the test data of the problem from the generation script.
+func generateProblemData(ctx *gin.Context) gin.HandlerFunc {
+	sqlExec, problem, rejected := queryProblem(ctx)
+	if rejected != nil {
+		return rejected
+	}
+	reports, err := sandbox.Generate(sqlExec, *problem)
+	if err != nil {
+		return reply.Err(err)
+	}
+	return reply.Success(http.StatusOK, map[string]interface{}{
+		"list":  reports,
+		"total": len(reports),
+	})
+}
+
+// checkInvariants judges all tagged solutions and reports those whose verdict differs from the tag.
+func checkInvariants(ctx *gin.Context) gin.HandlerFunc {
+	sqlExec, problem, rejected := queryProblem(ctx)
+	if rejected != nil {
+		return rejected
+	}
+	reports, err := sandbox.CheckInvariants(sqlExec, *problem)
+	if err != nil {
+		return reply.Err(err)
+	}
+	mismatched := 0
+	for _, report := range reports {
+		if !report.Matched {
+			mismatched++
+		}
+	}
+	return reply.Success(http.StatusOK, map[string]interface{}{
+		"list":       reports,
+		"total":      len(reports),
+		"mismatched": mismatched,
+	})
+}
+
+// suggestTimeLimit proposes time limits from the timings of the correct solutions.
+func suggestTimeLimit(ctx *gin.Context) gin.HandlerFunc {
+	sqlExec, problem, rejected := queryProblem(ctx)
+	if rejected != nil {
+		return rejected
+	}
+	report, err := sandbox.SuggestTimeLimit(sqlExec, *problem)
+	if err != nil {
+		return reply.Err(err)
+	}
+	return reply.Success(http.StatusOK, map[string]interface{}{
+		"data": report,
+	})
+}
+
+// stressTest compares a candidate solution with a trusted one on generated inputs.
+func stressTest(ctx *gin.Context) gin.HandlerFunc {
+	var request sandbox.StressRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		return reply.ErrorWithMessage(err, "invalid param")
+	}
+	if err := request.Valid(); err != nil {
+		return reply.Err(err)
+	}
+	sqlExec, err := db.GetSqlExec(ctx.Request.Context(), "problem")
+	if err != nil {
+		return reply.Err(err)
+	}
+	problem, rejected := ownProblem(ctx, sqlExec, request.PID)
+	if rejected != nil {
+		return rejected
+	}
+	report, err := sandbox.Stress(sqlExec, *problem, request)
+	if err != nil {
+		return reply.Err(err)
+	}
+	return reply.Success(http.StatusOK, map[string]interface{}{
+		"data": report,
+	})
+}
+
+// lintProblemData lints the input and output file of the test, only the files with issues are reported.
+func lintProblemData(proData model.ProblemData, normalize bool) ([]utils.LintReport, error) {
+	reports := make([]utils.LintReport, 0, 2)
+	for _, file := range []string{proData.InputFile, proData.OutputFile} {
+		report, err := utils.LintFile(file, normalize)
+		if err != nil {
+			return nil, err
+		}
+		if len(report.Issues) != 0 {
+			reports = append(reports, *report)
+		}
+	}
+	return reports, nil
+}
+
+// lintProblemDatas lints the stored test data of the problem. With normalize=true the files are
+// normalized and the md5 in problem_data recomputed.
+func lintProblemDatas(ctx *gin.Context) gin.HandlerFunc {
+	pid := ctx.Query("pid")
+	if pid == "" {
+		return reply.Err(errors.Errorf("invalid param pid: %v", pid))
+	}
+	normalize := ctx.Query("normalize") == "true"
+	sqlExec, err := db.GetSqlExec(ctx.Request.Context(), "problem")
+	if err != nil {
+		return reply.Err(err)
+	}
+	if _, rejected := ownProblem(ctx, sqlExec, pid); rejected != nil {
+		return rejected
+	}
+	proDatas, err := model.GetProblemData(sqlExec, map[string]interface{}{
+		"pid": pid,
+	})
+	if err != nil {
+		return reply.Err(err)
+	}
+	lints := make([]utils.LintReport, 0)
+	for _, proData := range proDatas {
+		reports, err := lintProblemData(proData, normalize)
+		if err != nil {
+			return reply.Err(err)
+		}
+		lints = append(lints, reports...)
+		if !normalize || len(reports) == 0 {
+			continue
+		}
+		if err := proData.CalculMD5(); err != nil {
+			return reply.Err(err)
+		}
+		if _, err := model.UpdateProblemDataMD5(sqlExec, proData); err != nil {
+			return reply.Err(err)
+		}
+	}
+	return reply.Success(http.StatusOK, map[string]interface{}{
+		"list":  lints,
+		"total": len(lints),
+	})
+}
+
+// setProblemDataFlag returns the handler setting a flag (is_sample, is_pretest) of test cases to
+// `value`, the sample endpoint also accepts its original `sample` field.
+func setProblemDataFlag(flag string) func(ctx *gin.Context) gin.HandlerFunc {
+	return func(ctx *gin.Context) gin.HandlerFunc {
+		var (
+			request = struct {
+				PID    int   `json:"pid"`
+				IDs    []int `json:"ids"`
+				Value  *bool `json:"value"`
+				Sample *bool `json:"sample"`
+			}{}
+		)
+		if err := ctx.ShouldBindJSON(&request); err != nil {
+			return reply.ErrorWithMessage(err, "invalid param")
+		}
+		if request.Value == nil && flag == "is_sample" {
+			request.Value = request.Sample
+		}
+		if request.Value == nil {
+			return reply.Err(errors.Errorf("invalid param value"))
+		}
+		sqlExec, err := db.GetSqlExec(ctx.Request.Context(), "problem")
+		if err != nil {
+			return reply.Err(err)
+		}
+		if _, rejected := ownProblem(ctx, sqlExec, request.PID); rejected != nil {
+			return rejected
+		}
+		rows, err := model.SetProblemDataFlag(sqlExec, request.PID, request.IDs, flag, *request.Value)
+		if err != nil {
+			return reply.Err(err)
+		}
+		return reply.Success(http.StatusOK, map[string]interface{}{
+			"data": rows,
+		})
+	}
+}
+
+// setProblemDataSubtask moves test cases to a subtask.
+func setProblemDataSubtask(ctx *gin.Context) gin.HandlerFunc {
+	var (
+		request = struct {
+			PID     int   `json:"pid"`
+			IDs     []int `json:"ids"`
+			Subtask int   `json:"subtask"`
+		}{}
+	)
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		return reply.ErrorWithMessage(err, "invalid param")
+	}
+	sqlExec, err := db.GetSqlExec(ctx.Request.Context(), "problem")
+	if err != nil {
+		return reply.Err(err)
+	}
+	if _, rejected := ownProblem(ctx, sqlExec, request.PID); rejected != nil {
+		return rejected
+	}
+	rows, err := model.SetProblemDataSubtask(sqlExec, request.PID, request.IDs, request.Subtask)
+	if err != nil {
+		return reply.Err(err)
+	}
+	return reply.Success(http.StatusOK, map[string]interface{}{
+		"data": rows,
+	})
+}
+
+// answerSQL writes the answers of a SQL problem with the result sets of the reference query.
+func answerSQL(ctx *gin.Context) gin.HandlerFunc {
+	sqlExec, problem, rejected := queryProblem(ctx)
+	if rejected != nil {
+		return rejected
+	}
+	if problem.Type != model.SQLProblem {
+		return reply.Err(errors.Errorf("problem %d is not a SQL problem.", problem.ID))
+	}
+	if err := sandbox.AnswerSQL(sqlExec, *problem); err != nil {
+		return reply.Err(err)
+	}
+	return reply.Success(http.StatusOK, nil)
+}
+
+// updateSignature sets the function signature of a problem, an empty signature turns it back into
+// a normal problem.
+func updateSignature(ctx *gin.Context) gin.HandlerFunc {
+	var (
+		request = struct {
+			PID       int64  `json:"pid"`
+			Signature string `json:"signature"`
+		}{}
+	)
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		return reply.ErrorWithMessage(err, "invalid param")
+	}
+	var starters map[string]string
+	if request.Signature != "" {
+		sig, err := driver.ParseSignature(request.Signature)
+		if err != nil {
+			return reply.ErrorWithMessage(err, "invalid signature")
+		}
+		request.Signature = sig.String()
+		starters = driver.Starters(sig)
+	}
+	sqlExec, err := db.GetSqlExec(ctx.Request.Context(), "problem")
+	if err != nil {
+		return reply.Err(err)
+	}
+	if _, rejected := ownProblem(ctx, sqlExec, request.PID); rejected != nil {
+		return rejected
+	}
+	_, err = sqlExec.Exec("UPDATE problem SET signature = ? WHERE id = ?", request.Signature, request.PID)
+	if err != nil {
+		return reply.Err(errors.Wrap(err, "db error."))
+	}
+	return reply.Success(http.StatusOK, map[string]interface{}{
+		"starters": starters,
+	})
+}
+
+// updateFunctionCases replaces the test data of a function problem with typed JSON test cases.
+func updateFunctionCases(ctx *gin.Context) gin.HandlerFunc {
+	var (
+		request = struct {
+			PID   int64         `json:"pid"`
+			Cases []driver.Case `json:"cases"`
+		}{}
+	)
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		return reply.ErrorWithMessage(err, "invalid param")
+	}
+	sqlExec, err := db.GetSqlExec(ctx.Request.Context(), "problem")
+	if err != nil {
+		return reply.Err(err)
+	}
+	problem, rejected := ownProblem(ctx, sqlExec, request.PID)
+	if rejected != nil {
+		return rejected
+	}
+	if problem.Signature == "" {
+		return reply.Err(errors.Errorf("problem %d has no signature.", problem.ID))
+	}
+	if err := sandbox.WriteCases(sqlExec, *problem, request.Cases); err != nil {
+		return reply.Err(err)
+	}
+	return reply.Success(http.StatusOK, map[string]interface{}{
+		"total": len(request.Cases),
+	})
+}
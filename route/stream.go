@@ -0,0 +1,270 @@
+package route
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/easyAation/scaffold/db"
+	"github.com/easyAation/scaffold/reply"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+
+	"online_judge/JudgeServer/common"
+	"online_judge/JudgeServer/middleware"
+	"online_judge/JudgeServer/model"
+	"online_judge/JudgeServer/sandbox"
+	"online_judge/JudgeServer/scoreboard"
+	"online_judge/JudgeServer/stream"
+)
+
+const (
+	// standingsInterval is the minimum interval between two standings events of a stream.
+	standingsInterval = time.Second
+	// heartbeat is the interval of the events keeping idle streams open. The standings are also
+	// checked on every heartbeat since they change with time too, when frozen for example.
+	heartbeat = 30 * time.Second
+)
+
+type standingsKey struct {
+	cid    int64
+	frozen bool
+}
+
+type cachedStandings struct {
+	version int64
+	value   interface{}
+}
+
+// standingsCache keeps the standings of the contests until their next change, see stream.Hub.
+var standingsCache = struct {
+	sync.Mutex
+	entries map[standingsKey]cachedStandings
+}{
+	entries: make(map[standingsKey]cachedStandings),
+}
+
+// contestStandings returns the standings of the contest as shown to the user.
+func contestStandings(ctx context.Context, sqlExec *db.SqlExec, contest *model.Contest, uid string) (interface{}, error) {
+	if (contest.Mode == model.OIMode || contest.Mode == model.IOIMode) && hidesResults(contest, uid) {
+		return []scoreboard.ScoreRow{}, nil
+	}
+	key := standingsKey{
+		cid:    contest.ID,
+		frozen: contest.Mode == model.ICPCMode && contest.Frozen(time.Now()) && !middleware.IsAdmin(uid),
+	}
+	version := stream.Default.Version(stream.ContestTopic(contest.ID))
+	standingsCache.Lock()
+	cached, ok := standingsCache.entries[key]
+	standingsCache.Unlock()
+	if ok && cached.version == version {
+		return cached.value, nil
+	}
+
+	names, err := entrantNames(ctx, sqlExec)
+	if err != nil {
+		return nil, err
+	}
+	var standings interface{}
+	switch contest.Mode {
+	case model.MarathonMode:
+		standings, err = scoreboard.Marathon(sqlExec, *contest, names)
+	case model.OIMode, model.IOIMode:
+		standings, err = scoreboard.LoadScores(sqlExec, *contest, names)
+	default:
+		standings, err = scoreboard.LoadICPC(sqlExec, *contest, names, key.frozen)
+	}
+	if err != nil {
+		return nil, err
+	}
+	standingsCache.Lock()
+	standingsCache.entries[key] = cachedStandings{version: version, value: standings}
+	standingsCache.Unlock()
+	return standings, nil
+}
+
+// rankStream is the stream of a contest: a `standings` event is sent on connection and after every
+// change, and a `clarification` event for every clarification the user can read.
+func rankStream(ctx *gin.Context) gin.HandlerFunc {
+	cid := ctx.Query("cid")
+	if cid == "" {
+		return reply.Err(errors.Errorf("invalid param cid: %v", cid))
+	}
+	sqlExec, err := db.GetSqlExec(ctx.Request.Context(), "problem")
+	if err != nil {
+		return reply.Err(err)
+	}
+	contest, err := model.GetOneContest(sqlExec, map[string]interface{}{
+		"id": cid,
+	})
+	if err != nil {
+		return reply.Err(err)
+	}
+	uid := middleware.GetCurrentID(ctx)
+	entrant, _, err := contestEntrant(sqlExec, contest.ID, uid)
+	if err != nil {
+		return reply.Err(err)
+	}
+	events, cancel := stream.Default.Subscribe(stream.ContestTopic(contest.ID))
+	clarifications, cancelClarifications := stream.Default.Subscribe(stream.ClarificationTopic(contest.ID))
+
+	return func(ctx *gin.Context) {
+		defer cancel()
+		defer cancelClarifications()
+		var (
+			ticker = time.NewTicker(standingsInterval)
+			last   []byte
+			dirty  = true
+			ticks  int
+		)
+		defer ticker.Stop()
+		ctx.Stream(func(w io.Writer) bool {
+			if dirty {
+				// the contest changes on unfreeze.
+				if contest, err = model.GetOneContest(sqlExec, map[string]interface{}{
+					"id": cid,
+				}); err != nil {
+					ctx.SSEvent("error", err.Error())
+					return false
+				}
+				standings, err := contestStandings(ctx, sqlExec, contest, uid)
+				if err != nil {
+					ctx.SSEvent("error", err.Error())
+					return false
+				}
+				data, err := json.Marshal(map[string]interface{}{"list": standings})
+				if err != nil {
+					ctx.SSEvent("error", err.Error())
+					return false
+				}
+				dirty = false
+				if !bytes.Equal(data, last) {
+					last = data
+					ctx.SSEvent("standings", string(data))
+					return true
+				}
+			}
+			for {
+				select {
+				case <-ctx.Request.Context().Done():
+					return false
+				case <-events:
+					dirty = true
+				case e := <-clarifications:
+					if c := e.Data.(model.Clarification); c.VisibleTo(uid, entrant, middleware.IsAdmin(uid)) {
+						ctx.SSEvent(e.Name, c)
+						return true
+					}
+				case <-ticker.C:
+					ticks++
+					if ticks%int(heartbeat/standingsInterval) == 0 {
+						ctx.SSEvent("ping", "")
+						dirty = true
+						return true
+					}
+					if dirty {
+						return true
+					}
+				}
+			}
+		})
+	}
+}
+
+// submissionStream streams the progress of a submission until its final result. The stream may be
+// opened before the submission is sent, its ID is chosen by the client.
+func submissionStream(ctx *gin.Context) gin.HandlerFunc {
+	sid := ctx.Query("sid")
+	if sid == "" {
+		return reply.Err(errors.Errorf("invalid param sid: %v", sid))
+	}
+	events, cancel := stream.Default.Subscribe(stream.SubmissionTopic(sid))
+	final, err := judgedProgress(ctx, sid)
+	if err != nil {
+		cancel()
+		return reply.Err(err)
+	}
+
+	return func(ctx *gin.Context) {
+		defer cancel()
+		if final != nil {
+			ctx.SSEvent("status", final)
+			return
+		}
+		ticker := time.NewTicker(heartbeat)
+		defer ticker.Stop()
+		ctx.Stream(func(w io.Writer) bool {
+			select {
+			case <-ctx.Request.Context().Done():
+				return false
+			case e := <-events:
+				ctx.SSEvent(e.Name, e.Data)
+				return !e.Data.(sandbox.Progress).Final
+			case <-ticker.C:
+				ctx.SSEvent("ping", "")
+				return true
+			}
+		})
+	}
+}
+
+// judgedProgress returns the final progress of the submission if it is already saved.
+func judgedProgress(ctx *gin.Context, sid string) (*sandbox.Progress, error) {
+	sqlExec, err := db.GetSqlExec(ctx.Request.Context(), "problem")
+	if err != nil {
+		return nil, err
+	}
+	progress := &sandbox.Progress{
+		SubmitID: sid,
+		Final:    true,
+	}
+	submits, err := model.GetSubmits(ctx, map[string]interface{}{"submit_id": sid})
+	if err != nil {
+		return nil, err
+	}
+	if len(submits) != 0 {
+		progress.Status = submits[0].Result
+		return progress, nil
+	}
+	css, err := model.GetContestSubmit(sqlExec, map[string]interface{}{"submit_id": sid})
+	if err != nil {
+		return nil, err
+	}
+	if len(css) == 0 {
+		return nil, nil
+	}
+	contest, err := model.GetOneContest(sqlExec, map[string]interface{}{
+		"id": css[0].CID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	uid := middleware.GetCurrentID(ctx)
+	entrant, _, err := contestEntrant(sqlExec, contest.ID, uid)
+	if err != nil {
+		return nil, err
+	}
+	if hidesSubmit(contest, &css[0], uid, entrant) {
+		hideResult(&css[0])
+	}
+	progress.Status = css[0].Result
+	return progress, nil
+}
+
+// publishResult ends the stream of the submission with its result, a hidden result is only shown
+// as Submitted.
+func publishResult(submitID string, res *sandbox.Result, hidden bool) {
+	progress := sandbox.Progress{
+		SubmitID: submitID,
+		Status:   res.Status,
+		Final:    true,
+		Result:   res,
+	}
+	if hidden {
+		progress.Status, progress.Result = common.Submitted, nil
+	}
+	sandbox.PublishProgress(progress)
+}
@@ -0,0 +1,259 @@
+package route
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/easyAation/scaffold/db"
+	"github.com/easyAation/scaffold/reply"
+	"github.com/easyAation/scaffold/router"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+
+	"online_judge/JudgeServer/middleware"
+	"online_judge/JudgeServer/model"
+)
+
+func TeamRouteModule() router.ModuleRoute {
+	routes := []*router.Router{
+		router.NewRouter("/v1/team/create",
+			http.MethodPost,
+			reply.Wrap(createTeam),
+		),
+		router.NewRouter("/v1/team/members",
+			http.MethodPost,
+			reply.Wrap(updateTeamMembers),
+		),
+		router.NewRouter("/v1/team/accept",
+			http.MethodPost,
+			reply.Wrap(acceptTeam),
+		),
+		router.NewRouter("/v1/team/leave",
+			http.MethodPost,
+			reply.Wrap(leaveTeam),
+		),
+		router.NewRouter("/v1/team/list",
+			http.MethodGet,
+			reply.Wrap(listTeams),
+		),
+	}
+	return router.ModuleRoute{
+		MiddleWares: []router.MiddleWare{middleware.VerifyLogin},
+		Routers:     routes,
+	}
+}
+
+// checkAccounts rejects the unknown accounts.
+func checkAccounts(ctx *gin.Context, uids []string) error {
+	accounts, err := model.GetAccounts(ctx, nil)
+	if err != nil {
+		return err
+	}
+	known := make(map[string]bool, len(accounts))
+	for _, ac := range accounts {
+		known[ac.ID] = true
+	}
+	for _, uid := range uids {
+		if !known[uid] {
+			return errors.Errorf("account %s not found.", uid)
+		}
+	}
+	return nil
+}
+
+// createTeam creates a team of the user and invites the given members.
+func createTeam(ctx *gin.Context) gin.HandlerFunc {
+	var (
+		request = struct {
+			Name    string   `json:"name"`
+			Members []string `json:"members"`
+		}{}
+	)
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		return reply.ErrorWithMessage(err, "invalid param")
+	}
+	if err := checkAccounts(ctx, request.Members); err != nil {
+		return reply.Err(err)
+	}
+	sqlExec, err := db.GetSqlExec(ctx.Request.Context(), "problem")
+	if err != nil {
+		return reply.Err(err)
+	}
+	id, err := model.AddTeam(sqlExec, model.Team{
+		Name:      request.Name,
+		CreatedBy: middleware.GetCurrentID(ctx),
+	}, request.Members)
+	if err != nil {
+		return reply.Err(err)
+	}
+	return reply.Success(http.StatusOK, map[string]interface{}{
+		"id": id,
+	})
+}
+
+// loadTeam loads the team given by id.
+func loadTeam(sqlExec *db.SqlExec, id int64) (*model.Team, error) {
+	teams, err := model.GetTeams(sqlExec, map[string]interface{}{
+		"id": id,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(teams) == 0 {
+		return nil, errors.Errorf("team %d not found.", id)
+	}
+	return &teams[0], nil
+}
+
+// updateTeamMembers sets the members of a team created by the user, the new members are invited.
+func updateTeamMembers(ctx *gin.Context) gin.HandlerFunc {
+	var (
+		request = struct {
+			TeamID  int64    `json:"team_id"`
+			Members []string `json:"members"`
+		}{}
+	)
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		return reply.ErrorWithMessage(err, "invalid param")
+	}
+	if err := checkAccounts(ctx, request.Members); err != nil {
+		return reply.Err(err)
+	}
+	sqlExec, err := db.GetSqlExec(ctx.Request.Context(), "problem")
+	if err != nil {
+		return reply.Err(err)
+	}
+	team, err := loadTeam(sqlExec, request.TeamID)
+	if err != nil {
+		return reply.Err(err)
+	}
+	uid := middleware.GetCurrentID(ctx)
+	if team.CreatedBy != uid && !middleware.IsAdmin(uid) {
+		return replyCode(http.StatusForbidden, CodeNotParticipant,
+			errors.Errorf("you did not create team %d.", request.TeamID))
+	}
+	if err := model.UpdateTeamMembers(sqlExec, *team, request.Members); err != nil {
+		return reply.Err(err)
+	}
+	return reply.Success(http.StatusOK, nil)
+}
+
+// acceptTeam accepts the invitation of the user to a team. The user can not take part in the
+// contests the team is registered to otherwise, while they run.
+func acceptTeam(ctx *gin.Context) gin.HandlerFunc {
+	var (
+		request = struct {
+			TeamID int64 `json:"team_id"`
+		}{}
+	)
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		return reply.ErrorWithMessage(err, "invalid param")
+	}
+	sqlExec, err := db.GetSqlExec(ctx.Request.Context(), "problem")
+	if err != nil {
+		return reply.Err(err)
+	}
+	var (
+		uid     = middleware.GetCurrentID(ctx)
+		entrant = model.TeamEntrant(request.TeamID)
+	)
+	participations, err := model.GetContestParticipants(sqlExec, map[string]interface{}{
+		"uid": entrant,
+	})
+	if err != nil {
+		return reply.Err(err)
+	}
+	for _, p := range participations {
+		contest, err := model.GetOneContest(sqlExec, map[string]interface{}{
+			"id": p.CID,
+		})
+		if err != nil {
+			return reply.Err(err)
+		}
+		if !time.Now().Before(contest.EndAt) {
+			continue
+		}
+		if err := checkEntrants(sqlExec, p.CID, entrant, []string{uid}); err != nil {
+			return reply.Err(err)
+		}
+	}
+	rows, err := model.AcceptTeam(sqlExec, request.TeamID, uid)
+	if err != nil {
+		return reply.Err(err)
+	}
+	if rows == 0 {
+		return reply.Err(errors.Errorf("you are not invited to team %d.", request.TeamID))
+	}
+	return reply.Success(http.StatusOK, nil)
+}
+
+// leaveTeam removes the user from a team, or declines the invitation. The creator can not leave.
+func leaveTeam(ctx *gin.Context) gin.HandlerFunc {
+	var (
+		request = struct {
+			TeamID int64 `json:"team_id"`
+		}{}
+	)
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		return reply.ErrorWithMessage(err, "invalid param")
+	}
+	sqlExec, err := db.GetSqlExec(ctx.Request.Context(), "problem")
+	if err != nil {
+		return reply.Err(err)
+	}
+	team, err := loadTeam(sqlExec, request.TeamID)
+	if err != nil {
+		return reply.Err(err)
+	}
+	uid := middleware.GetCurrentID(ctx)
+	if team.CreatedBy == uid {
+		return reply.Err(errors.Errorf("the creator can not leave team %d.", request.TeamID))
+	}
+	if _, err := model.LeaveTeam(sqlExec, request.TeamID, uid); err != nil {
+		return reply.Err(err)
+	}
+	return reply.Success(http.StatusOK, nil)
+}
+
+// listTeams returns the teams of the user and the invitations, with their members.
+func listTeams(ctx *gin.Context) gin.HandlerFunc {
+	sqlExec, err := db.GetSqlExec(ctx.Request.Context(), "problem")
+	if err != nil {
+		return reply.Err(err)
+	}
+	memberships, err := model.GetTeamMembers(sqlExec, map[string]interface{}{
+		"uid": middleware.GetCurrentID(ctx),
+	})
+	if err != nil {
+		return reply.Err(err)
+	}
+	type item struct {
+		model.Team
+		Entrant  string             `json:"entrant"`
+		Accepted bool               `json:"accepted"`
+		Members  []model.TeamMember `json:"members"`
+	}
+	list := make([]item, 0, len(memberships))
+	for _, m := range memberships {
+		team, err := loadTeam(sqlExec, m.TeamID)
+		if err != nil {
+			return reply.Err(err)
+		}
+		members, err := model.GetTeamMembers(sqlExec, map[string]interface{}{
+			"team_id": m.TeamID,
+		})
+		if err != nil {
+			return reply.Err(err)
+		}
+		list = append(list, item{
+			Team:     *team,
+			Entrant:  model.TeamEntrant(team.ID),
+			Accepted: m.Accepted,
+			Members:  members,
+		})
+	}
+	return reply.Success(http.StatusOK, map[string]interface{}{
+		"list":  list,
+		"total": len(list),
+	})
+}
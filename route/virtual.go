@@ -0,0 +1,143 @@
+package route
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/easyAation/scaffold/db"
+	"github.com/easyAation/scaffold/reply"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+
+	"online_judge/JudgeServer/middleware"
+	"online_judge/JudgeServer/model"
+	"online_judge/JudgeServer/scoreboard"
+)
+
+// inVirtual reports whether the user takes the contest virtually now, the submissions are then
+// part of the virtual participation.
+func inVirtual(sqlExec *db.SqlExec, contest *model.Contest, uid string) (bool, error) {
+	if time.Now().Before(contest.EndAt) {
+		return false, nil
+	}
+	v, err := model.GetVirtualParticipation(sqlExec, contest.ID, uid)
+	if err != nil || v == nil {
+		return false, err
+	}
+	return v.Running(*contest, time.Now()), nil
+}
+
+// startVirtual starts a virtual participation of the user in an ended contest, now for the
+// duration of the contest. The original contestants can not take it again.
+func startVirtual(ctx *gin.Context) gin.HandlerFunc {
+	var (
+		request = struct {
+			CID int64 `json:"cid"`
+		}{}
+	)
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		return reply.ErrorWithMessage(err, "invalid param")
+	}
+	sqlExec, err := db.GetSqlExec(ctx.Request.Context(), "problem")
+	if err != nil {
+		return reply.Err(err)
+	}
+	contests, err := model.GetContest(sqlExec, map[string]interface{}{
+		"id": request.CID,
+	})
+	if err != nil {
+		return reply.Err(err)
+	}
+	if len(contests) == 0 {
+		return replyCode(http.StatusNotFound, CodeContestNotFound,
+			errors.Errorf("contest %d not found.", request.CID))
+	}
+	contest := &contests[0]
+	now := time.Now()
+	if now.Before(contest.EndAt) {
+		return reply.Err(errors.Errorf("contest %d has not ended.", contest.ID))
+	}
+	if contest.Mode == model.MarathonMode {
+		return reply.Err(errors.Errorf("marathon contests can not be taken virtually."))
+	}
+	uid := middleware.GetCurrentID(ctx)
+	_, registered, err := contestEntrant(sqlExec, contest.ID, uid)
+	if err != nil {
+		return reply.Err(err)
+	}
+	if registered {
+		return reply.Err(errors.Errorf("you took part in contest %d.", contest.ID))
+	}
+	v := model.VirtualParticipation{
+		CID:     contest.ID,
+		UID:     uid,
+		StartAt: now,
+	}
+	if _, err := model.AddVirtualParticipation(sqlExec, v); err != nil {
+		return reply.Err(err)
+	}
+	return reply.Success(http.StatusOK, map[string]interface{}{
+		"start_at": v.StartAt,
+		"end_at":   v.EndAt(*contest),
+	})
+}
+
+// virtualRank returns the standings of the virtual participation of the user: the original
+// contestants as they were at the elapsed time of the participation, and the user.
+func virtualRank(ctx *gin.Context) gin.HandlerFunc {
+	cid, err := strconv.ParseInt(ctx.Query("cid"), 10, 64)
+	if err != nil {
+		return reply.Err(errors.Errorf("invalid param cid: %v", ctx.Query("cid")))
+	}
+	sqlExec, err := db.GetSqlExec(ctx.Request.Context(), "problem")
+	if err != nil {
+		return reply.Err(err)
+	}
+	contest, err := model.GetOneContest(sqlExec, map[string]interface{}{
+		"id": cid,
+	})
+	if err != nil {
+		return reply.Err(err)
+	}
+	v, err := model.GetVirtualParticipation(sqlExec, cid, middleware.GetCurrentID(ctx))
+	if err != nil {
+		return reply.Err(err)
+	}
+	if v == nil {
+		return reply.Err(errors.Errorf("you do not take contest %d virtually.", cid))
+	}
+	names, err := entrantNames(ctx, sqlExec)
+	if err != nil {
+		return reply.Err(err)
+	}
+	submits, err := model.GetContestSubmit(sqlExec, map[string]interface{}{
+		"cid": cid,
+	})
+	if err != nil {
+		return reply.Err(err)
+	}
+	now := time.Now()
+	submits = scoreboard.VirtualSubmits(*contest, *v, submits, now)
+
+	var standings interface{}
+	switch contest.Mode {
+	case model.OIMode, model.IOIMode:
+		standings, err = scoreboard.Scores(sqlExec, *contest, submits, names)
+	default:
+		pids, err := scoreboard.ContestPIDs(sqlExec, cid)
+		if err != nil {
+			return reply.Err(err)
+		}
+		standings = scoreboard.ICPC(*contest, pids, submits, names, false)
+	}
+	if err != nil {
+		return reply.Err(err)
+	}
+	return reply.Success(http.StatusOK, map[string]interface{}{
+		"data":     standings,
+		"start_at": v.StartAt,
+		"end_at":   v.EndAt(*contest),
+		"running":  v.Running(*contest, now),
+	})
+}
@@ -0,0 +1,88 @@
+package sandbox
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/easyAation/scaffold/db"
+
+	"online_judge/JudgeServer/common"
+	"online_judge/JudgeServer/model"
+)
+
+// Checker checks an output against the answer of a test, with the checker program of the problem
+// if it has one, otherwise with Compare.
+type Checker struct {
+	checker *Exec
+}
+
+func NewChecker(sqlExec *db.SqlExec, pid int) (*Checker, error) {
+	programs, err := NewPrograms(sqlExec, pid)
+	if err != nil {
+		return nil, err
+	}
+	checkers := programs.OfType(model.CheckerProgram)
+	if len(checkers) == 0 {
+		return &Checker{}, nil
+	}
+	checker, err := programs.Program(checkers[0].Name)
+	if err != nil {
+		return nil, err
+	}
+	return &Checker{
+		checker: &checker,
+	}, nil
+}
+
+// Check returns the verdict of the output file on the test.
+func (c *Checker) Check(proData model.ProblemData, outputFile string) string {
+	if c.checker == nil {
+		return Compare(proData.OutputFile, outputFile)
+	}
+	e := *c.checker
+	e.InputFile, e.OutputFile = os.DevNull, os.DevNull
+	e.Args = append(append([]string{}, e.Args...), proData.InputFile, outputFile, proData.OutputFile)
+	e.TimeLimit, e.MemoryLimit = toolTimeLimit, toolMemoryLimit
+	res, err := e.Run()
+	if err != nil {
+		return common.InternalError
+	}
+	switch res.Code {
+	case 0:
+		return common.Accept
+	case 4:
+		// the checker rejects the output with a non zero exit code.
+		return common.WrongAnswer
+	}
+	return common.SysteamError
+}
+
+// Score runs the checker of an optimization problem. The checker accepts the output with exit code
+// 0 and prints the raw score as the first token of its stdout.
+func (c *Checker) Score(proData model.ProblemData, outputFile string) (string, float64) {
+	if c.checker == nil {
+		return common.SysteamError, 0
+	}
+	scoreFile := outputFile + ".score"
+	defer os.Remove(scoreFile)
+	e := *c.checker
+	e.InputFile, e.OutputFile = os.DevNull, scoreFile
+	e.Args = append(append([]string{}, e.Args...), proData.InputFile, outputFile, proData.OutputFile)
+	e.TimeLimit, e.MemoryLimit = toolTimeLimit, toolMemoryLimit
+	res, err := e.Run()
+	if err != nil {
+		return common.InternalError, 0
+	}
+	switch res.Code {
+	case 0:
+	case 4:
+		return common.WrongAnswer, 0
+	default:
+		return common.SysteamError, 0
+	}
+	var raw float64
+	if _, err := fmt.Sscan(readHead(scoreFile, 64), &raw); err != nil {
+		return common.SysteamError, 0
+	}
+	return common.Accept, raw
+}
@@ -0,0 +1,27 @@
+package sandbox
+
+import (
+	"bytes"
+	"io/ioutil"
+
+	"online_judge/JudgeServer/common"
+)
+
+// Compare compares the output file with the answer file the same way judge does with the stored md5.
+func Compare(answerFile, outputFile string) string {
+	answer, err := ioutil.ReadFile(answerFile)
+	if err != nil {
+		return common.InternalError
+	}
+	output, err := ioutil.ReadFile(outputFile)
+	if err != nil {
+		return common.InternalError
+	}
+	if bytes.Equal(answer, output) {
+		return common.Accept
+	}
+	if bytes.Equal(bytes.TrimSpace(answer), bytes.TrimSpace(output)) {
+		return common.PresentationError
+	}
+	return common.WrongAnswer
+}
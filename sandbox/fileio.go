@@ -0,0 +1,63 @@
+package sandbox
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+
+	"online_judge/JudgeServer/common"
+	"online_judge/JudgeServer/compile"
+	"online_judge/JudgeServer/model"
+)
+
+// fileIO prepares the run of a test of a file I/O problem. The program runs in a fresh working
+// directory holding only the input of this test under the name given by the problem, so it can
+// not reach the files of the other tests by relative paths. It returns the Exec and the file the
+// program writes its output to.
+func fileIO(e Exec, problem *model.Problem, proData model.ProblemData, outputFile string) (Exec, string, error) {
+	workDir := outputFile + ".dir"
+	if err := os.RemoveAll(workDir); err != nil {
+		return e, "", errors.WithStack(err)
+	}
+	if err := os.MkdirAll(workDir, os.ModePerm); err != nil {
+		return e, "", errors.WithStack(err)
+	}
+	// the program may run with another uid, see common.JudgeConfig.
+	if err := os.Chmod(workDir, 0777); err != nil {
+		return e, "", errors.WithStack(err)
+	}
+	input, err := ioutil.ReadFile(proData.InputFile)
+	if err != nil {
+		return e, "", errors.WithStack(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(workDir, problem.FileInput), input, 0644); err != nil {
+		return e, "", errors.WithStack(err)
+	}
+	e.WorkDir = workDir
+	e.InputFile = os.DevNull
+	// the c_cpp and general rules forbid opening files for writing. The other tests stay out of
+	// reach by absolute paths since the problem data is not readable by the programs, see
+	// ProtectProblemDir.
+	switch e.Seccomp {
+	case "", compile.SeccompCCpp:
+		e.Seccomp = compile.SeccompCCppFileIO
+	case compile.SeccompGeneral:
+		e.Seccomp = compile.SeccompGeneralFileIO
+	}
+	return e, filepath.Join(workDir, problem.FileOutput), nil
+}
+
+// ProtectProblemDir makes the problem data unreadable by the programs when they run with RunUID:
+// the sandbox opens the input and output files before switching to RunUID.
+func ProtectProblemDir() error {
+	if common.Config.Judge.RunUID <= 0 {
+		return nil
+	}
+	dir := common.Config.SandBox.ProblemDir
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(os.Chmod(dir, 0700))
+}
@@ -0,0 +1,322 @@
+package sandbox
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/easyAation/scaffold/db"
+	"github.com/pkg/errors"
+
+	"online_judge/JudgeServer/common"
+	"online_judge/JudgeServer/model"
+)
+
+// ReferenceSolution is the name of the solution program used to produce the answers.
+const ReferenceSolution = "main"
+
+// limits for setter programs (generators, validators, reference solutions).
+const (
+	toolTimeLimit   = 10000
+	toolMemoryLimit = 512 * 1024 * 1024
+)
+
+// ScriptLine is one line of a generation script, for example `gen_random 100000 seed=5 > 12`.
+type ScriptLine struct {
+	Generator string
+	Args      []string
+	Test      int
+}
+
+// ParseScript parses the generation script, empty lines and lines starting with '#' are skipped.
+func ParseScript(script string) ([]ScriptLine, error) {
+	var (
+		lines = make([]ScriptLine, 0)
+		tests = make(map[int]bool)
+	)
+	for i, line := range strings.Split(script, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 3 || fields[len(fields)-2] != ">" {
+			return nil, errors.Errorf("line %d: expect `generator [args...] > test`", i+1)
+		}
+		test, err := strconv.Atoi(fields[len(fields)-1])
+		if err != nil || test <= 0 {
+			return nil, errors.Errorf("line %d: invalid test index %s", i+1, fields[len(fields)-1])
+		}
+		if tests[test] {
+			return nil, errors.Errorf("line %d: test %d is generated twice", i+1, test)
+		}
+		tests[test] = true
+		lines = append(lines, ScriptLine{
+			Generator: fields[0],
+			Args:      fields[1 : len(fields)-2],
+			Test:      test,
+		})
+	}
+	if len(lines) == 0 {
+		return nil, errors.Errorf("empty script")
+	}
+	return lines, nil
+}
+
+// GenerateReport is the result of one script line.
+type GenerateReport struct {
+	Test    int    `json:"test"`
+	Line    string `json:"line"`
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+}
+
+// Programs compiles the setter programs of a problem on demand.
+type Programs struct {
+	pid      int
+	programs map[string]model.ProblemProgram
+	execs    map[string]Exec
+}
+
+func NewPrograms(sqlExec *db.SqlExec, pid int) (*Programs, error) {
+	list, err := model.GetProblemPrograms(sqlExec, map[string]interface{}{
+		"pid": pid,
+	})
+	if err != nil {
+		return nil, err
+	}
+	ps := &Programs{
+		pid:      pid,
+		programs: make(map[string]model.ProblemProgram),
+		execs:    make(map[string]Exec),
+	}
+	for _, p := range list {
+		ps.programs[p.Name] = p
+	}
+	return ps, nil
+}
+
+// Get returns the program with the name.
+func (ps *Programs) Get(name string) (model.ProblemProgram, bool) {
+	p, ok := ps.programs[name]
+	return p, ok
+}
+
+// OfType returns all programs of the type.
+func (ps *Programs) OfType(typ string) []model.ProblemProgram {
+	list := make([]model.ProblemProgram, 0)
+	for _, p := range ps.programs {
+		if p.Type == typ {
+			list = append(list, p)
+		}
+	}
+	return list
+}
+
+// Program compiles the program if necessary and returns the Exec running it.
+func (ps *Programs) Program(name string) (Exec, error) {
+	if e, ok := ps.execs[name]; ok {
+		return e, nil
+	}
+	p, ok := ps.programs[name]
+	if !ok {
+		return Exec{}, errors.Errorf("program %s not found.", name)
+	}
+	sandBox, err := NewSandBox(Request{
+		ID:        fmt.Sprintf("program_%d_%s", ps.pid, p.Name),
+		ProblemID: ps.pid,
+		Code:      p.Code,
+		Language:  p.Language,
+	})
+	if err != nil {
+		return Exec{}, err
+	}
+	exeFile, err := sandBox.Build()
+	if err != nil {
+		return Exec{}, errors.WithMessage(err, fmt.Sprintf("compile %s fail.", name))
+	}
+	ps.execs[name] = program(sandBox.Compiler, exeFile)
+	return ps.execs[name], nil
+}
+
+// runTool runs a setter program with the tool limits, any verdict other than success is an error.
+func runTool(e Exec, inputFile, outputFile string, args []string) error {
+	e.InputFile, e.OutputFile = inputFile, outputFile
+	e.Args = append(append([]string{}, e.Args...), args...)
+	e.TimeLimit, e.MemoryLimit = toolTimeLimit, toolMemoryLimit
+	res, err := e.Run()
+	if err != nil {
+		return err
+	}
+	if res.Code != 0 {
+		return errors.Errorf("%s", judge(res.Code, outputFile, model.ProblemData{}))
+	}
+	return nil
+}
+
+// Validate runs the validator of the problem, if any, on the input file.
+func (ps *Programs) Validate(inputFile string) error {
+	validators := ps.OfType(model.ValidatorProgram)
+	if len(validators) == 0 {
+		return nil
+	}
+	validator, err := ps.Program(validators[0].Name)
+	if err != nil {
+		return err
+	}
+	if err := runTool(validator, inputFile, os.DevNull, nil); err != nil {
+		return errors.WithMessage(err, "validator rejected the input")
+	}
+	return nil
+}
+
+// Answer runs the reference solution on the input file and writes the answer to outputFile.
+func (ps *Programs) Answer(inputFile, outputFile string) error {
+	solution, err := ps.Program(ReferenceSolution)
+	if err != nil {
+		return err
+	}
+	if err := runTool(solution, inputFile, outputFile, nil); err != nil {
+		return errors.WithMessage(err, "reference solution failed")
+	}
+	return nil
+}
+
+// TestFiles returns the input and output file of the numbered test, the layout is the same as uploaded data.
+func TestFiles(pid, test int) (string, string) {
+	return testFiles(filepath.Join(common.Config.SandBox.ProblemDir, strconv.Itoa(pid)), test)
+}
+
+func testFiles(dir string, test int) (string, string) {
+	name := strconv.Itoa(test)
+	dir = filepath.Join(dir, name)
+	return filepath.Join(dir, name+".in"), filepath.Join(dir, name+".out")
+}
+
+// Generate rebuilds the whole test set of the problem from its generation script. Every input is
+// produced by a generator, checked by the validator and answered by the reference solution. The
+// tests are generated in a staging directory, which replaces the directory of the problem once all
+// lines succeed and the problem data is updated. Stress test counterexamples are kept.
+func Generate(sqlExec *db.SqlExec, problem model.Problem) ([]GenerateReport, error) {
+	lines, err := ParseScript(problem.GenScript)
+	if err != nil {
+		return nil, err
+	}
+	programs, err := NewPrograms(sqlExec, int(problem.ID))
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := programs.Get(ReferenceSolution); !ok {
+		return nil, errors.Errorf("reference solution %s not found.", ReferenceSolution)
+	}
+
+	if err := os.MkdirAll(common.Config.SandBox.ProblemDir, os.ModePerm); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	tmpDir, err := ioutil.TempDir(common.Config.SandBox.ProblemDir, fmt.Sprintf("%d_generate_", problem.ID))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	var (
+		reports  = make([]GenerateReport, 0, len(lines))
+		proDatas = make([]model.ProblemData, 0, len(lines))
+		failed   bool
+	)
+	for _, line := range lines {
+		report := GenerateReport{
+			Test:   line.Test,
+			Line:   fmt.Sprintf("%s %s > %d", line.Generator, strings.Join(line.Args, " "), line.Test),
+			Status: common.Accept,
+		}
+		proData, err := generateTest(programs, int(problem.ID), tmpDir, line)
+		if err != nil {
+			failed = true
+			report.Status = common.SysteamError
+			report.Message = err.Error()
+		} else {
+			proDatas = append(proDatas, *proData)
+		}
+		reports = append(reports, report)
+	}
+	if failed {
+		return reports, nil
+	}
+
+	old, err := model.GetProblemData(sqlExec, map[string]interface{}{
+		"pid": problem.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i, line := range lines {
+		proDatas[i].InputFile, proDatas[i].OutputFile = TestFiles(int(problem.ID), line.Test)
+	}
+	if err := model.ReplaceProblemDatas(sqlExec, int(problem.ID), proDatas); err != nil {
+		return nil, err
+	}
+	var (
+		problemDir = filepath.Join(common.Config.SandBox.ProblemDir, strconv.Itoa(int(problem.ID)))
+		oldDir     = tmpDir + "_old"
+	)
+	if err := swapDir(tmpDir, problemDir, oldDir); err != nil {
+		if len(old) == 0 {
+			model.DeleteProblemData(sqlExec, int(problem.ID))
+		} else {
+			model.ReplaceProblemDatas(sqlExec, int(problem.ID), old)
+		}
+		return nil, err
+	}
+	os.Rename(filepath.Join(oldDir, "stress"), filepath.Join(problemDir, "stress"))
+	os.RemoveAll(oldDir)
+	return reports, nil
+}
+
+// swapDir replaces dir by staging, the old dir is moved to backup and restored on failure.
+func swapDir(staging, dir, backup string) error {
+	if err := os.Rename(dir, backup); err != nil && !os.IsNotExist(err) {
+		return errors.WithStack(err)
+	}
+	if err := os.Rename(staging, dir); err != nil {
+		os.Rename(backup, dir)
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// generateTest generates the test of the line in dir.
+func generateTest(programs *Programs, pid int, dir string, line ScriptLine) (*model.ProblemData, error) {
+	if p, ok := programs.Get(line.Generator); !ok || p.Type != model.GeneratorProgram {
+		return nil, errors.Errorf("generator %s not found.", line.Generator)
+	}
+	generator, err := programs.Program(line.Generator)
+	if err != nil {
+		return nil, err
+	}
+	inputFile, outputFile := testFiles(dir, line.Test)
+	if err := os.MkdirAll(filepath.Dir(inputFile), os.ModePerm); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if err := runTool(generator, os.DevNull, inputFile, line.Args); err != nil {
+		return nil, errors.WithMessage(err, "generator failed")
+	}
+	if err := programs.Validate(inputFile); err != nil {
+		return nil, err
+	}
+	if err := programs.Answer(inputFile, outputFile); err != nil {
+		return nil, err
+	}
+	proData := model.ProblemData{
+		PID:        pid,
+		InputFile:  inputFile,
+		OutputFile: outputFile,
+	}
+	if err := proData.CalculMD5(); err != nil {
+		return nil, err
+	}
+	return &proData, nil
+}
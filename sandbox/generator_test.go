@@ -0,0 +1,56 @@
+package sandbox
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseScript(t *testing.T) {
+	lines, err := ParseScript("# samples\ngen_random 100000 seed=5 > 12\n\ngen_line 3 > 1\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lines) != 2 {
+		t.Fatalf("expect 2 lines, but result is %d", len(lines))
+	}
+	if lines[0].Generator != "gen_random" || lines[0].Test != 12 || len(lines[0].Args) != 2 {
+		t.Errorf("unexpected line %+v", lines[0])
+	}
+
+	for _, script := range []string{"", "gen 1 2", "gen > x", "gen > 1\ngen > 1"} {
+		if _, err := ParseScript(script); err == nil {
+			t.Errorf("expect error for script %q", script)
+		}
+	}
+}
+
+func TestSwapDir(t *testing.T) {
+	root, err := ioutil.TempDir("", "swap")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	var (
+		staging = filepath.Join(root, "staging")
+		dir     = filepath.Join(root, "1")
+		backup  = filepath.Join(root, "backup")
+	)
+	os.Mkdir(dir, os.ModePerm)
+	if err := swapDir(staging, dir, backup); err == nil {
+		t.Fatal("expect error without staging directory")
+	}
+	if _, err := os.Stat(dir); err != nil {
+		t.Errorf("expect the directory restored, but got %v", err)
+	}
+
+	os.Mkdir(staging, os.ModePerm)
+	ioutil.WriteFile(filepath.Join(staging, "new"), nil, os.ModePerm)
+	if err := swapDir(staging, dir, backup); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "new")); err != nil {
+		t.Errorf("expect the staging directory in place, but got %v", err)
+	}
+}
@@ -0,0 +1,81 @@
+package sandbox
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/easyAation/scaffold/db"
+	"github.com/pkg/errors"
+
+	"online_judge/JudgeServer/common"
+	"online_judge/JudgeServer/compile"
+	"online_judge/JudgeServer/model"
+)
+
+var (
+	commentRegexp = regexp.MustCompile(`(?s)//[^\n]*|/\*.*?\*/|"(\\.|[^"\\])*"|'(\\.|[^'\\])*'`)
+	mainRegexp    = regexp.MustCompile(`\bmain\s*\(`)
+
+	// errors of the submission itself, reported as Compile Error.
+	errMainDefined    = errors.New("main function is provided by the grader.")
+	errGraderLanguage = errors.New("language does not support graders.")
+)
+
+// DefinesMain reports whether the C/C++ code declares a main function, comments and string
+// literals are ignored.
+func DefinesMain(code string) bool {
+	return mainRegexp.MatchString(commentRegexp.ReplaceAllString(code, " "))
+}
+
+// Graders returns the grader sources and headers of the problem for the language.
+func Graders(sqlExec *db.SqlExec, pid int, language string) ([]model.ProblemProgram, error) {
+	programs, err := model.GetProblemPrograms(sqlExec, map[string]interface{}{
+		"pid":      pid,
+		"language": language,
+	})
+	if err != nil {
+		return nil, err
+	}
+	graders := make([]model.ProblemProgram, 0)
+	for _, p := range programs {
+		if p.Type == model.GraderProgram || p.Type == model.HeaderProgram {
+			graders = append(graders, p)
+		}
+	}
+	return graders, nil
+}
+
+// prepareGrader writes the grader files of a function problem next to the submission, the code
+// is then compiled and linked together with the grader sources.
+func (s *SandBox) prepareGrader(sqlExec *db.SqlExec) error {
+	graders, err := Graders(sqlExec, s.ProblemID, s.Language)
+	if err != nil {
+		return err
+	}
+	if len(graders) == 0 {
+		return nil
+	}
+	if DefinesMain(s.Code) {
+		return errMainDefined
+	}
+	if _, ok := s.Compiler.(compile.LinkCompiler); !ok {
+		return errGraderLanguage
+	}
+	s.includeDir = filepath.Join(common.Config.Compile.CodeDir, fmt.Sprintf("%s_%d_grader", s.ID, s.ProblemID))
+	if err := os.MkdirAll(s.includeDir, os.ModePerm); err != nil {
+		return errors.WithStack(err)
+	}
+	for _, grader := range graders {
+		file := filepath.Join(s.includeDir, grader.Name)
+		if err := ioutil.WriteFile(file, []byte(grader.Code), os.ModePerm); err != nil {
+			return errors.WithStack(err)
+		}
+		if grader.Type == model.GraderProgram {
+			s.graderFiles = append(s.graderFiles, file)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,21 @@
+package sandbox
+
+import (
+	"testing"
+)
+
+func TestDefinesMain(t *testing.T) {
+	for code, expect := range map[string]bool{
+		"int main() { return 0; }":                    true,
+		"int main (void)\n{}":                         true,
+		"int solve(int n) { return n; }":              false,
+		"// int main() {}\nint solve() { return 1; }": false,
+		"/* main(\n */ int f() { return 0; }":         false,
+		`const char *s = "main()";`:                   false,
+		"int domain(int x) { return x; }":             false,
+	} {
+		if DefinesMain(code) != expect {
+			t.Errorf("%q: expect %v", code, expect)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package sandbox
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/easyAation/scaffold/db"
+
+	"online_judge/JudgeServer/common"
+	"online_judge/JudgeServer/model"
+)
+
+// SolutionReport is the outcome of one tagged solution in the invariant check. FailedTests numbers
+// the tests from 1, as the UI does.
+type SolutionReport struct {
+	Name        string   `json:"name"`
+	Language    string   `json:"language"`
+	Tag         string   `json:"tag"`
+	Verdict     string   `json:"verdict"`
+	Matched     bool     `json:"matched"`
+	FailedTests []int    `json:"failed_tests"`
+	Cases       []Result `json:"cases"`
+}
+
+// MatchTag reports whether the overall verdict satisfies the expected outcome of a solution.
+func MatchTag(tag, verdict string) bool {
+	switch tag {
+	case model.TagAccept:
+		return verdict == common.Accept
+	case model.TagWrongAnswer:
+		return verdict == common.WrongAnswer || verdict == common.PresentationError
+	case model.TagTimeLimit:
+		return verdict == common.TimeLimit
+	case model.TagMemoryLimit:
+		return verdict == common.MemoryLimit
+	case model.TagRuntimeError:
+		return verdict == common.RuntimeError
+	case model.TagFailing:
+		return verdict != common.Accept && verdict != common.CompileError
+	}
+	return false
+}
+
+// CheckInvariants judges every solution of the problem on the current test set and compares the
+// verdict with its tag. Mismatched solutions are listed first.
+func CheckInvariants(sqlExec *db.SqlExec, problem model.Problem) ([]SolutionReport, error) {
+	solutions, err := model.GetProblemPrograms(sqlExec, map[string]interface{}{
+		"pid":  problem.ID,
+		"type": model.SolutionProgram,
+	})
+	if err != nil {
+		return nil, err
+	}
+	reports := make([]SolutionReport, 0, len(solutions))
+	for _, solution := range solutions {
+		report, err := checkSolution(problem, solution)
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, *report)
+	}
+	sort.SliceStable(reports, func(i, j int) bool {
+		return !reports[i].Matched && reports[j].Matched
+	})
+	return reports, nil
+}
+
+func checkSolution(problem model.Problem, solution model.ProblemProgram) (*SolutionReport, error) {
+	judger, err := NewJudger(Request{
+		ID:          fmt.Sprintf("check_%d_%s", problem.ID, solution.Name),
+		ProblemID:   int(problem.ID),
+		Code:        solution.Code,
+		Language:    solution.Language,
+		TimeLimit:   problem.TimeLimit,
+		MemoryLimit: problem.MemoryLimit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	res, err := judger.Run()
+	if err != nil {
+		return nil, err
+	}
+	tag := solution.Tag
+	if solution.Name == ReferenceSolution {
+		tag = model.TagAccept
+	}
+	report := &SolutionReport{
+		Name:        solution.Name,
+		Language:    solution.Language,
+		Tag:         tag,
+		Verdict:     res.Status,
+		Matched:     MatchTag(tag, res.Status),
+		FailedTests: make([]int, 0),
+		Cases:       res.Cases,
+	}
+	for _, c := range res.Cases {
+		if c.Status != common.Accept {
+			report.FailedTests = append(report.FailedTests, c.Index+1)
+		}
+	}
+	return report, nil
+}
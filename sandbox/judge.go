@@ -7,12 +7,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
-	"log"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"sort"
-	"strings"
+	"syscall"
 
 	"github.com/easyAation/scaffold/db"
 	"github.com/pkg/errors"
@@ -28,13 +27,32 @@ type SandBox struct {
 	Request
 	codeFile string
 	exeFile  string
+	// set for function problems, see prepareGrader.
+	includeDir  string
+	graderFiles []string
 }
 type Result struct {
 	Index  int
 	Time   int64 `json:"real_time"`
 	Memory int64 `json:"memory"`
 	Code   int   `json:"result"`
+	Signal int   `json:"signal"`
 	Status string
+	// Score is the percentage scored, only set for scored problems.
+	Score float64 `json:"score,omitempty"`
+	// Raw is the score printed by the checker of optimization problems.
+	Raw      float64            `json:"raw_score,omitempty"`
+	RawScore *model.SubmitScore `json:"-"`
+	// Detail is only set on sample cases.
+	Detail *CaseDetail `json:"detail,omitempty"`
+	// Kept is the output kept for a failed case.
+	Kept *model.SubmitOutput `json:"-"`
+	// Subtask of the test case, see model.ProblemData.
+	Subtask int `json:"subtask,omitempty"`
+	// Cases holds the result of every test case, only set on the overall result.
+	Cases []Result `json:"cases,omitempty"`
+	// Subtasks holds the score of every subtask of a standard problem, only set on the overall result.
+	Subtasks []model.SubmitSubtask `json:"subtasks,omitempty"`
 }
 
 type Request struct {
@@ -44,8 +62,13 @@ type Request struct {
 	Language    string `json:"language"`
 	TimeLimit   int64  `json:"time_limit"` // nsec
 	MemoryLimit int64  `json:"memory_limit"`
+	// Pretest judges the code on the pretests only, set by the server during contests.
+	Pretest bool `json:"-"`
 }
 
+// defaultMaxOutputSize is the limit of the bytes a program writes when not configured.
+const defaultMaxOutputSize = 64 << 20
+
 func judge(code int, file1 string, proData model.ProblemData) string {
 	if code == 1 || code == 2 {
 		return common.TimeLimit
@@ -54,7 +77,7 @@ func judge(code int, file1 string, proData model.ProblemData) string {
 		return common.MemoryLimit
 	}
 	if code == 4 {
-		return common.MemoryLimit
+		return common.RuntimeError
 	}
 	if code == 5 {
 		return common.SysteamError
@@ -77,12 +100,113 @@ func judge(code int, file1 string, proData model.ProblemData) string {
 	return common.Accept
 
 }
-func buildCommandArgs(values map[string]interface{}) string {
+
+// buildCommandArgs returns the sandbox arguments, one --key=value per option so that no value is
+// interpreted by a shell.
+func buildCommandArgs(values map[string]interface{}) []string {
 	var args = make([]string, 0, len(values))
 	for op, value := range values {
+		// repeated options such as --args are passed as a string slice.
+		if multi, ok := value.([]string); ok {
+			for _, v := range multi {
+				args = append(args, fmt.Sprintf("--%s=%v", op, v))
+			}
+			continue
+		}
 		args = append(args, fmt.Sprintf("--%s=%v", op, value))
 	}
-	return " " + strings.Join(args, " ")
+	return args
+}
+
+// Exec describes one execution of an already compiled program inside the sandbox.
+type Exec struct {
+	ExeFile     string
+	InputFile   string
+	OutputFile  string
+	ErrorFile   string
+	Args        []string
+	TimeLimit   int64
+	MemoryLimit int64
+	// Seccomp is the seccomp rule of the sandbox, c_cpp if empty.
+	Seccomp string
+	// WorkDir is the working directory of the program, used by file I/O problems.
+	WorkDir string
+	// heap is set for the runtimes limiting their memory themselves.
+	heap compile.HeapLimiter
+}
+
+// program returns the Exec running a compiled file, the program and arguments are given by the
+// compiler when it is a compile.Runner.
+func program(compiler compile.Compiler, exeFile string) Exec {
+	if runner, ok := compiler.(compile.Runner); ok {
+		exe, args, seccomp := runner.RunCommand(exeFile)
+		heap, _ := compiler.(compile.HeapLimiter)
+		return Exec{
+			ExeFile: exe,
+			Args:    args,
+			Seccomp: seccomp,
+			heap:    heap,
+		}
+	}
+	return Exec{
+		ExeFile: exeFile,
+	}
+}
+
+// Run executes the program and returns the raw sandbox result, Status is left empty.
+func (e Exec) Run() (*Result, error) {
+	// the paths must not depend on the working directory.
+	for _, file := range []*string{&e.ExeFile, &e.InputFile, &e.OutputFile, &e.ErrorFile} {
+		if *file != "" {
+			*file, _ = filepath.Abs(*file)
+		}
+	}
+	maxOutputSize := common.Config.Judge.MaxOutputSize
+	if maxOutputSize <= 0 {
+		maxOutputSize = defaultMaxOutputSize
+	}
+	values := map[string]interface{}{
+		"max_output_size":   maxOutputSize,
+		"exe_path":          e.ExeFile,
+		"input_path":        e.InputFile,
+		"output_path":       e.OutputFile,
+		"max_cpu_time":      e.TimeLimit,
+		"max_real_time":     e.TimeLimit,
+		"memory_limit":      e.MemoryLimit,
+		"seccomp_rule_name": compile.SeccompCCpp,
+	}
+	switch e.Seccomp {
+	case "":
+	case compile.SeccompNone:
+		delete(values, "seccomp_rule_name")
+	default:
+		values["seccomp_rule_name"] = e.Seccomp
+	}
+	if e.ErrorFile != "" {
+		values["error_path"] = e.ErrorFile
+	}
+	if e.heap != nil && e.MemoryLimit > 0 {
+		values["memory_limit_check_only"] = 1
+		e.Args = append(e.heap.HeapArgs(e.MemoryLimit), e.Args...)
+	}
+	if len(e.Args) != 0 {
+		values["args"] = e.Args
+	}
+	if common.Config.Judge.RunUID > 0 {
+		values["uid"] = common.Config.Judge.RunUID
+		values["gid"] = common.Config.Judge.RunGID
+	}
+	cmd := exec.Command(common.Config.SandBox.Exe, buildCommandArgs(values)...)
+	cmd.Dir = e.WorkDir
+	msg, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, errors.Wrap(err, string(msg))
+	}
+	var result Result
+	if err := json.Unmarshal(msg, &result); err != nil {
+		return nil, errors.Wrap(err, string(msg))
+	}
+	return &result, nil
 }
 
 func NewSandBox(request Request) (*SandBox, error) {
@@ -105,6 +229,10 @@ func (s *SandBox) SaveCodeFile() error {
 		s.codeFile += ".cpp"
 	case common.GoLanguage:
 		s.codeFile += ".go"
+	case common.JavaLanguage:
+		s.codeFile += ".java"
+	case common.PythonLanguage:
+		s.codeFile += ".py"
 	default:
 		return errors.Errorf("%s not support.", s.Language)
 	}
@@ -118,27 +246,69 @@ func (s *SandBox) compile() error {
 	if s.exeFile != "" {
 		return nil
 	}
-	var err error
-	s.exeFile, err = s.Compile(s.codeFile, common.Config.Compile.ExeDir+string(os.PathSeparator)+s.ID)
+	exeFile, err := filepath.Abs(common.Config.Compile.ExeDir + string(os.PathSeparator) + s.ID)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if len(s.graderFiles) != 0 {
+		s.exeFile, err = s.Compiler.(compile.LinkCompiler).CompileFiles(append([]string{s.codeFile}, s.graderFiles...),
+			s.includeDir, exeFile)
+	} else {
+		s.exeFile, err = s.Compile(s.codeFile, exeFile)
+	}
 	if err != nil {
 		return err
 	}
 	return nil
 }
-func (s *SandBox) Run() (*Result, error) {
+
+// Build saves and compiles the code, returns the executable file.
+func (s *SandBox) Build() (string, error) {
 	if err := s.SaveCodeFile(); err != nil {
-		return nil, errors.Wrap(err, "save file error.")
+		return "", errors.Wrap(err, "save file error.")
 	}
 	if err := s.compile(); err != nil {
-		return &Result{
-			Status: common.CompileError,
-		}, nil
+		return "", err
 	}
+	return s.exeFile, nil
+}
 
+func (s *SandBox) Run() (*Result, error) {
 	sqlExec, err := db.GetSqlExec(context.Background(), "problem")
 	if err != nil {
 		return nil, errors.Wrap(err, "get sqlExec error.")
 	}
+	problem, err := model.GetOneProblem(sqlExec, map[string]interface{}{
+		"id": s.ProblemID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	switch problem.Type {
+	case model.OutputOnlyProblem:
+		return nil, errors.Errorf("problem %d only accepts output files.", problem.ID)
+	case model.SQLProblem:
+		return nil, errors.Errorf("problem %d only accepts %s queries.", problem.ID, common.SQLLanguage)
+	}
+	if err := s.prepareSignature(problem); err != nil {
+		return nil, err
+	}
+	if err := s.SaveCodeFile(); err != nil {
+		return nil, errors.Wrap(err, "save file error.")
+	}
+	s.progress(common.Compiling, 0)
+	if err := s.prepareGrader(sqlExec); err == errMainDefined || err == errGraderLanguage {
+		return &Result{
+			Status: common.CompileError,
+		}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := s.compile(); err != nil {
+		return &Result{
+			Status: common.CompileError,
+		}, nil
+	}
 
 	problemData, err := model.GetProblemData(sqlExec, map[string]interface{}{
 		"pid": s.ProblemID,
@@ -146,38 +316,94 @@ func (s *SandBox) Run() (*Result, error) {
 	if err != nil {
 		return nil, errors.Wrap(err, "")
 	}
+	if s.Pretest {
+		problemData = pretests(problemData)
+	}
+	var checker *Checker
+	if problem.Type == model.OptimizationProblem {
+		if checker, err = NewChecker(sqlExec, s.ProblemID); err != nil {
+			return nil, err
+		}
+	}
 	results := make([]Result, 0, len(problemData))
 	for index, prodata := range problemData {
+		s.progress(common.Running, index+1)
 		outputFile := common.Config.SandBox.OutPutDir + string(os.PathSeparator) + s.ID + fmt.Sprintf("_%d", index)
-		args := common.Config.SandBox.Exe + buildCommandArgs(map[string]interface{}{
-			"exe_path":          s.exeFile,
-			"input_path":        prodata.InputFile,
-			"output_path":       outputFile,    // outputFile,
-			"max_cpu_time":      s.TimeLimit,   // s.TimeLimit,
-			"max_real_time":     s.TimeLimit,   // s.TimeLimit,
-			"memory_limit":      s.MemoryLimit, // s.MemoryLimit,
-			"seccomp_rule_name": "c_cpp",
-		})
-		cmd := exec.Command("/usr/bin/bash", "-c", args)
-		msg, err := cmd.CombinedOutput()
-		fmt.Println(string(msg))
+		errorFile := outputFile + ".err"
+		e := program(s.Compiler, s.exeFile)
+		e.InputFile, e.OutputFile, e.ErrorFile = prodata.InputFile, outputFile, errorFile
+		e.TimeLimit, e.MemoryLimit = s.TimeLimit, s.MemoryLimit
+		if problem.FileInput != "" {
+			if e, outputFile, err = fileIO(e, problem, prodata, outputFile); err != nil {
+				return nil, err
+			}
+		}
+		res, err := e.Run()
 		if err != nil {
-			return nil, errors.Wrap(err, string(msg))
+			return nil, err
 		}
-		var result = Result{
-			Index: index,
+		var result = *res
+		result.Index, result.Subtask = index, prodata.Subtask
+		if checker != nil && result.Code == 0 {
+			result.Status, result.Raw = checker.Score(prodata, outputFile)
+		} else {
+			result.Status = judge(result.Code, outputFile, prodata)
 		}
-		if err := json.Unmarshal(msg, &result); err != nil {
-			log.Print(err)
-			continue
+		if result.Code == 4 && result.Signal == int(syscall.SIGXFSZ) {
+			result.Status = common.OutputLimit
+		}
+		if checker != nil {
+			result.RawScore = &model.SubmitScore{
+				SubmitID: s.ID,
+				PID:      s.ProblemID,
+				DataID:   prodata.ID,
+				Accepted: result.Status == common.Accept,
+			}
+			if result.RawScore.Accepted {
+				result.RawScore.Raw = result.Raw
+			}
+		}
+		if prodata.IsSample {
+			result.Detail = sampleDetail(prodata, outputFile, errorFile)
+		}
+		if result.Status != common.Accept {
+			result.Kept = keepOutput(s.ID, index, result.Status, prodata, outputFile, errorFile)
 		}
-		result.Status = judge(result.Code, outputFile, prodata)
 		results = append(results, result)
 		fmt.Printf("output file: %s\n", outputFile)
+		if e.WorkDir != "" {
+			os.RemoveAll(e.WorkDir)
+		}
 	}
 
+	res := summarize(results)
+	res.Score, res.Subtasks = scoreSubtasks(s.ID, s.ProblemID, res.Cases)
+	if s.Pretest && res.Status == common.Accept {
+		res.Status = common.PretestsPassed
+	}
+	return &res, nil
+}
+
+// pretests returns the test cases flagged as pretest, all of them if none is flagged.
+func pretests(problemData []model.ProblemData) []model.ProblemData {
+	list := make([]model.ProblemData, 0, len(problemData))
+	for _, prodata := range problemData {
+		if prodata.IsPretest {
+			list = append(list, prodata)
+		}
+	}
+	if len(list) == 0 {
+		return problemData
+	}
+	return list
+}
+
+// summarize sorts the case results and computes the overall result, the status is the one
+// of the first failed case.
+func summarize(results []Result) Result {
 	res := Result{
 		Status: common.Accept,
+		Cases:  results,
 	}
 	sort.Slice(results, func(i, j int) bool {
 		return results[i].Index < results[j].Index
@@ -194,5 +420,5 @@ func (s *SandBox) Run() (*Result, error) {
 			break
 		}
 	}
-	return &res, nil
+	return res
 }
@@ -0,0 +1,84 @@
+package sandbox
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/easyAation/scaffold/db"
+
+	"online_judge/JudgeServer/common"
+	"online_judge/JudgeServer/model"
+	"online_judge/JudgeServer/utils"
+)
+
+// default values of common.OutputConfig.
+const (
+	defaultOutputSize      = 4096
+	defaultOutputRetention = 7 * 24 * time.Hour
+)
+
+// keepOutput keeps the head of stdout and stderr of a failed case.
+func keepOutput(submitID string, index int, status string, proData model.ProblemData,
+	outputFile, errorFile string) *model.SubmitOutput {
+	size := int64(common.Config.Output.MaxSize)
+	if size <= 0 {
+		size = defaultOutputSize
+	}
+	diffPos, err := utils.FirstDifference(proData.OutputFile, outputFile)
+	if err != nil {
+		diffPos = 0
+	}
+	return &model.SubmitOutput{
+		SubmitID:  submitID,
+		CaseIndex: index,
+		Status:    status,
+		Output:    readHead(outputFile, size),
+		Stderr:    readHead(errorFile, size),
+		DiffPos:   diffPos,
+	}
+}
+
+// KeptOutputs returns the outputs kept for the failed cases of the result.
+func (r *Result) KeptOutputs() []model.SubmitOutput {
+	outputs := make([]model.SubmitOutput, 0)
+	for _, c := range r.Cases {
+		if c.Kept != nil {
+			outputs = append(outputs, *c.Kept)
+		}
+	}
+	return outputs
+}
+
+// RawScores returns the raw scores of the cases accepted by the checker of an optimization problem.
+func (r *Result) RawScores() []model.SubmitScore {
+	scores := make([]model.SubmitScore, 0)
+	for _, c := range r.Cases {
+		if c.RawScore != nil {
+			scores = append(scores, *c.RawScore)
+		}
+	}
+	return scores
+}
+
+// CleanOutputs removes the expired kept outputs every interval and keeps the total size under
+// the configured cap, it never returns.
+func CleanOutputs(interval time.Duration) {
+	for range time.Tick(interval) {
+		sqlExec, err := db.GetSqlExec(context.Background(), "problem")
+		if err != nil {
+			log.Print(err)
+			continue
+		}
+		retention := common.Config.Output.Retention.D()
+		if retention <= 0 {
+			retention = defaultOutputRetention
+		}
+		removed, err := model.CleanSubmitOutputs(sqlExec, time.Now().Add(-retention), common.Config.Output.TotalSize)
+		if err != nil {
+			log.Print(err)
+			continue
+		}
+		log.Printf("%d submit outputs removed.", removed)
+	}
+}
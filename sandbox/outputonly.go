@@ -0,0 +1,90 @@
+package sandbox
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/easyAation/scaffold/db"
+	"github.com/pkg/errors"
+
+	"online_judge/JudgeServer/common"
+	"online_judge/JudgeServer/model"
+)
+
+// AnswerDir returns the directory of the answer files of an output-only submission, they are kept
+// there for rejudging.
+func AnswerDir(submitID string) string {
+	return filepath.Join(common.Config.SandBox.OutPutDir, filepath.Base(submitID))
+}
+
+// answers lists the answer files kept for the submission by test name.
+func answers(submitID string) (map[string]string, error) {
+	files, err := ioutil.ReadDir(AnswerDir(submitID))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	outputs := make(map[string]string, len(files))
+	for _, file := range files {
+		name := file.Name()
+		outputs[name[:len(name)-len(filepath.Ext(name))]] = filepath.Join(AnswerDir(submitID), name)
+	}
+	return outputs, nil
+}
+
+// TestName returns the name of the test, the base name of its input file without extension.
+// The answer files of output-only submissions are matched with the tests by this name.
+func TestName(proData model.ProblemData) string {
+	name := filepath.Base(proData.InputFile)
+	return name[:len(name)-len(filepath.Ext(name))]
+}
+
+// JudgeOutputs checks the answer files of an output-only submission, outputs maps the test names
+// to the uploaded files. Every test scores the same, a missing file is a wrong answer.
+func JudgeOutputs(sqlExec *db.SqlExec, problem model.Problem, submitID string,
+	outputs map[string]string) (*Result, error) {
+	problemData, err := model.GetProblemData(sqlExec, map[string]interface{}{
+		"pid": problem.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	checker, err := NewChecker(sqlExec, int(problem.ID))
+	if err != nil {
+		return nil, err
+	}
+	results := make([]Result, 0, len(problemData))
+	for index, proData := range problemData {
+		result := Result{
+			Index:  index,
+			Status: common.WrongAnswer,
+		}
+		outputFile, ok := outputs[TestName(proData)]
+		if ok {
+			result.Status = checker.Check(proData, outputFile)
+		} else {
+			outputFile = os.DevNull
+		}
+		if result.Status == common.Accept {
+			result.Score = 100
+		} else {
+			result.Kept = keepOutput(submitID, index, result.Status, proData, outputFile, "")
+		}
+		results = append(results, result)
+	}
+	res := summarize(results)
+	res.Score = averageScore(results)
+	return &res, nil
+}
+
+// averageScore returns the average score of the cases, in percentage.
+func averageScore(results []Result) float64 {
+	if len(results) == 0 {
+		return 0
+	}
+	var total float64
+	for _, result := range results {
+		total += result.Score
+	}
+	return total / float64(len(results))
+}
@@ -0,0 +1,38 @@
+package sandbox
+
+import (
+	"online_judge/JudgeServer/stream"
+)
+
+// Progress is a status transition of a submission: Pending, Compiling, Running test N and the
+// final result, published on the stream of the submission.
+type Progress struct {
+	SubmitID string `json:"submit_id"`
+	Status   string `json:"status"`
+	// Test is the running test, counted from 1.
+	Test  int  `json:"test,omitempty"`
+	Final bool `json:"final"`
+	// Result is the final result, it is not set when the result is hidden.
+	Result *Result `json:"result,omitempty"`
+}
+
+// PublishProgress publishes the progress on the stream of the submission.
+func PublishProgress(p Progress) {
+	stream.Default.Publish(stream.SubmissionTopic(p.SubmitID), stream.Event{
+		Name: "status",
+		Data: p,
+	})
+}
+
+// SubmitID identifies the submission in its progress.
+func (r Request) SubmitID() string {
+	return r.ID
+}
+
+func (r Request) progress(status string, test int) {
+	PublishProgress(Progress{
+		SubmitID: r.ID,
+		Status:   status,
+		Test:     test,
+	})
+}
@@ -0,0 +1,91 @@
+package sandbox
+
+import (
+	"log"
+	"runtime"
+	"runtime/debug"
+	"sync"
+
+	"online_judge/JudgeServer/common"
+)
+
+// priorities of the judge queue.
+const (
+	HighPriority = iota
+	LowPriority
+)
+
+type task struct {
+	judger Judger
+	done   chan taskResult
+}
+
+type taskResult struct {
+	res *Result
+	err error
+}
+
+var (
+	queueOnce sync.Once
+	highQueue = make(chan task)
+	lowQueue  = make(chan task)
+)
+
+func startWorkers() {
+	workers := common.Config.Judge.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	for i := 0; i < workers; i++ {
+		go worker()
+	}
+}
+
+// worker runs the queued tasks, low priority tasks only run when no high priority task is waiting.
+func worker() {
+	for {
+		var t task
+		select {
+		case t = <-highQueue:
+		default:
+			select {
+			case t = <-highQueue:
+			case t = <-lowQueue:
+			}
+		}
+		t.done <- run(t.judger)
+	}
+}
+
+// run runs the judger, a panic is reported as a system error of the submission so that the worker
+// keeps running.
+func run(judger Judger) (r taskResult) {
+	defer func() {
+		if p := recover(); p != nil {
+			log.Printf("judge %s panic: %v\n%s", judger.SubmitID(), p, debug.Stack())
+			r = taskResult{res: &Result{Status: common.SysteamError}}
+		}
+	}()
+	res, err := judger.Run()
+	return taskResult{res, err}
+}
+
+// Judge runs the judger through the judge queue and waits for the result.
+func Judge(judger Judger, priority int) (*Result, error) {
+	queueOnce.Do(startWorkers)
+	PublishProgress(Progress{
+		SubmitID: judger.SubmitID(),
+		Status:   common.Pending,
+	})
+	t := task{
+		judger: judger,
+		done:   make(chan taskResult, 1),
+	}
+	if priority == LowPriority {
+		lowQueue <- t
+	} else {
+		highQueue <- t
+	}
+	r := <-t.done
+	return r.res, r.err
+}
@@ -0,0 +1,22 @@
+package sandbox
+
+import (
+	"testing"
+
+	"online_judge/JudgeServer/common"
+)
+
+type panicJudger struct{}
+
+func (panicJudger) Run() (*Result, error) { panic("checker crashed") }
+
+func (panicJudger) SubmitID() string { return "panic" }
+
+func TestJudgePanic(t *testing.T) {
+	for i := 0; i < 2; i++ {
+		res, err := Judge(panicJudger{}, HighPriority)
+		if err != nil || res.Status != common.SysteamError {
+			t.Errorf("expect %s, but got %+v, %v", common.SysteamError, res, err)
+		}
+	}
+}
@@ -0,0 +1,262 @@
+package sandbox
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/easyAation/scaffold/db"
+	"github.com/pkg/errors"
+
+	"online_judge/JudgeServer/common"
+	"online_judge/JudgeServer/model"
+	"online_judge/JudgeServer/stream"
+	"online_judge/JudgeServer/utils"
+)
+
+// VerdictChange records the verdict of a submission before and after it is judged again.
+type VerdictChange struct {
+	SubmitID string `json:"submit_id"`
+	UID      string `json:"uid"`
+	PID      int    `json:"pid"`
+	CID      int64  `json:"cid,omitempty"`
+	Old      string `json:"old"`
+	New      string `json:"new"`
+}
+
+// problemCache loads every problem once.
+type problemCache struct {
+	sqlExec  *db.SqlExec
+	problems map[int]*model.Problem
+}
+
+func newProblemCache(sqlExec *db.SqlExec) *problemCache {
+	return &problemCache{
+		sqlExec:  sqlExec,
+		problems: make(map[int]*model.Problem),
+	}
+}
+
+func (c *problemCache) get(pid int) (*model.Problem, error) {
+	if problem, ok := c.problems[pid]; ok {
+		return problem, nil
+	}
+	problem, err := model.GetOneProblem(c.sqlExec, map[string]interface{}{
+		"id": pid,
+	})
+	if err != nil {
+		return nil, err
+	}
+	c.problems[pid] = problem
+	return problem, nil
+}
+
+// Rejudge judges the submission again with the limits of the problem, on the pretests only with
+// pretest. It goes through the judge queue with low priority, so new submissions are not delayed.
+func Rejudge(submit model.Submit, problem model.Problem, pretest bool) (*Result, error) {
+	if submit.Language == common.OutputLanguage {
+		return rejudgeOutputs(submit, problem)
+	}
+	judger, err := NewJudger(Request{
+		ID:          submit.SubmitID,
+		ProblemID:   submit.PID,
+		Code:        submit.Code,
+		Language:    submit.Language,
+		TimeLimit:   problem.TimeLimit,
+		MemoryLimit: problem.MemoryLimit,
+		Pretest:     pretest,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return Judge(judger, LowPriority)
+}
+
+// RejudgeSubmits judges the submissions and contest submissions again and reports the verdict
+// changes. Unless dryRun, the stored results are updated. The submissions of running pretest
+// contests are judged on the pretests, as when submitted.
+func RejudgeSubmits(sqlExec *db.SqlExec, submits []model.Submit, contestSubmits []model.ContestSubmit,
+	dryRun bool) ([]VerdictChange, error) {
+	var (
+		cache    = newProblemCache(sqlExec)
+		changes  = make([]VerdictChange, 0)
+		pretests = make(map[int64]bool)
+	)
+	rejudge := func(submit model.Submit, cid int64) (*VerdictChange, *Result, error) {
+		problem, err := cache.get(submit.PID)
+		if err != nil {
+			return nil, nil, err
+		}
+		pretest, ok := pretests[cid]
+		if !ok && cid != 0 {
+			contest, err := model.GetOneContest(sqlExec, map[string]interface{}{
+				"id": cid,
+			})
+			if err != nil {
+				return nil, nil, err
+			}
+			pretest = contest.Pretest && time.Now().Before(contest.EndAt)
+			pretests[cid] = pretest
+		}
+		res, err := Rejudge(submit, *problem, pretest)
+		if err != nil {
+			return nil, nil, err
+		}
+		change := &VerdictChange{
+			SubmitID: submit.SubmitID,
+			UID:      submit.UID,
+			PID:      submit.PID,
+			CID:      cid,
+			Old:      submit.Result,
+			New:      res.Status,
+		}
+		if dryRun {
+			return change, res, nil
+		}
+		if err := model.ReplaceSubmitOutputs(sqlExec, submit.SubmitID, res.KeptOutputs()); err != nil {
+			return nil, nil, err
+		}
+		if problem.Type == model.OptimizationProblem {
+			if err := model.SaveSubmitScores(sqlExec, submit.SubmitID, res.RawScores()); err != nil {
+				return nil, nil, err
+			}
+		}
+		if len(res.Subtasks) != 0 {
+			if err := model.SaveSubmitSubtasks(sqlExec, submit.SubmitID, res.Subtasks); err != nil {
+				return nil, nil, err
+			}
+		}
+		return change, res, nil
+	}
+
+	for _, submit := range submits {
+		change, res, err := rejudge(submit, 0)
+		if err != nil {
+			return nil, err
+		}
+		if change.Old != change.New {
+			changes = append(changes, *change)
+		}
+		if dryRun {
+			continue
+		}
+		_, err = model.UpdateSubmitBySID(sqlExec, submit.SubmitID, map[string]interface{}{
+			"result":   res.Status,
+			"run_time": res.Time,
+			"memory":   res.Memory,
+			"score":    res.Score,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, submit := range contestSubmits {
+		change, res, err := rejudge(submit.Submit, submit.CID)
+		if err != nil {
+			return nil, err
+		}
+		if change.Old != change.New {
+			changes = append(changes, *change)
+		}
+		if dryRun {
+			continue
+		}
+		_, err = model.UpdateContestSubmit(sqlExec, submit.ID, map[string]interface{}{
+			"result":   res.Status,
+			"run_time": res.Time,
+			"memory":   res.Memory,
+			"score":    res.Score,
+		})
+		if err != nil {
+			return nil, err
+		}
+		stream.ContestChanged(submit.CID)
+	}
+	return changes, nil
+}
+
+// states of a RejudgeJob.
+const (
+	JobRunning  = "running"
+	JobFinished = "finished"
+	JobFailed   = "failed"
+)
+
+// finished jobs are kept for an hour.
+const jobTTL = time.Hour
+
+// RejudgeJob is a rejudge running in the background, Changes is set once finished.
+type RejudgeJob struct {
+	ID         string          `json:"id"`
+	Status     string          `json:"status"`
+	Rejudged   int             `json:"rejudged"`
+	DryRun     bool            `json:"dry_run"`
+	Changes    []VerdictChange `json:"list"`
+	Error      string          `json:"error,omitempty"`
+	FinishedAt time.Time       `json:"finished_at,omitempty"`
+}
+
+var rejudgeJobs = struct {
+	sync.Mutex
+	jobs map[string]*RejudgeJob
+}{
+	jobs: make(map[string]*RejudgeJob),
+}
+
+// StartRejudge runs RejudgeSubmits in the background and returns the id of the job, see
+// GetRejudgeJob.
+func StartRejudge(submits []model.Submit, contestSubmits []model.ContestSubmit, dryRun bool) string {
+	job := &RejudgeJob{
+		ID:       utils.UUID(),
+		Status:   JobRunning,
+		Rejudged: len(submits) + len(contestSubmits),
+		DryRun:   dryRun,
+	}
+	rejudgeJobs.Lock()
+	for id, j := range rejudgeJobs.jobs {
+		if j.Status != JobRunning && time.Since(j.FinishedAt) > jobTTL {
+			delete(rejudgeJobs.jobs, id)
+		}
+	}
+	rejudgeJobs.jobs[job.ID] = job
+	rejudgeJobs.Unlock()
+
+	go func() {
+		var changes []VerdictChange
+		sqlExec, err := db.GetSqlExec(context.Background(), "problem")
+		if err == nil {
+			changes, err = RejudgeSubmits(sqlExec, submits, contestSubmits, dryRun)
+		}
+		rejudgeJobs.Lock()
+		defer rejudgeJobs.Unlock()
+		job.Status, job.Changes, job.FinishedAt = JobFinished, changes, time.Now()
+		if err != nil {
+			job.Status, job.Error = JobFailed, err.Error()
+		}
+	}()
+	return job.ID
+}
+
+// GetRejudgeJob returns the state of the rejudge job.
+func GetRejudgeJob(id string) (RejudgeJob, bool) {
+	rejudgeJobs.Lock()
+	defer rejudgeJobs.Unlock()
+	job, ok := rejudgeJobs.jobs[id]
+	if !ok {
+		return RejudgeJob{}, false
+	}
+	return *job, true
+}
+
+// rejudgeOutputs checks the kept answer files of an output-only submission again.
+func rejudgeOutputs(submit model.Submit, problem model.Problem) (*Result, error) {
+	sqlExec, err := db.GetSqlExec(context.Background(), "problem")
+	if err != nil {
+		return nil, errors.Wrap(err, "get sqlExec error.")
+	}
+	outputs, err := answers(submit.SubmitID)
+	if err != nil {
+		return nil, err
+	}
+	return JudgeOutputs(sqlExec, problem, submit.SubmitID, outputs)
+}
@@ -0,0 +1,84 @@
+package sandbox
+
+import (
+	"io"
+	"os"
+	"sort"
+
+	"github.com/easyAation/scaffold/db"
+
+	"online_judge/JudgeServer/model"
+	"online_judge/JudgeServer/utils"
+)
+
+// maxSampleSize is the max number of bytes of every file shown in a sample detail.
+const maxSampleSize = 64 * 1024
+
+// CaseDetail is the visible run detail of a sample case.
+type CaseDetail struct {
+	Input    string `json:"input"`
+	Output   string `json:"output"`
+	Expected string `json:"expected"`
+	Diff     string `json:"diff,omitempty"`
+	Stderr   string `json:"stderr,omitempty"`
+}
+
+// readHead reads at most n bytes of the file, a missing file reads as empty.
+func readHead(fileName string, n int64) string {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	data := make([]byte, n)
+	size, _ := io.ReadFull(f, data)
+	return string(data[:size])
+}
+
+func sampleDetail(proData model.ProblemData, outputFile, errorFile string) *CaseDetail {
+	detail := &CaseDetail{
+		Input:    readHead(proData.InputFile, maxSampleSize),
+		Output:   readHead(outputFile, maxSampleSize),
+		Expected: readHead(proData.OutputFile, maxSampleSize),
+		Stderr:   readHead(errorFile, maxSampleSize),
+	}
+	detail.Diff = utils.LineDiff(detail.Expected, detail.Output)
+	return detail
+}
+
+// Sample is a sample test shown in the problem detail.
+type Sample struct {
+	ID     int    `json:"id"`
+	Input  string `json:"input"`
+	Output string `json:"output"`
+}
+
+// Samples returns the sample tests of the problem. Problems without flagged samples fall back to
+// the single sample of the statement.
+func Samples(sqlExec *db.SqlExec, problem model.Problem) ([]Sample, error) {
+	proDatas, err := model.GetProblemData(sqlExec, map[string]interface{}{
+		"pid":       problem.ID,
+		"is_sample": 1,
+	})
+	if err != nil {
+		return nil, err
+	}
+	samples := make([]Sample, 0, len(proDatas))
+	for _, proData := range proDatas {
+		samples = append(samples, Sample{
+			ID:     proData.ID,
+			Input:  readHead(proData.InputFile, maxSampleSize),
+			Output: readHead(proData.OutputFile, maxSampleSize),
+		})
+	}
+	if len(samples) == 0 && (problem.CaseDataInput != "" || problem.CaseDataOutput != "") {
+		samples = append(samples, Sample{
+			Input:  problem.CaseDataInput,
+			Output: problem.CaseDataOutput,
+		})
+	}
+	sort.Slice(samples, func(i, j int) bool {
+		return samples[i].ID < samples[j].ID
+	})
+	return samples, nil
+}
@@ -0,0 +1,73 @@
+package sandbox
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/easyAation/scaffold/db"
+	"github.com/pkg/errors"
+
+	"online_judge/JudgeServer/driver"
+	"online_judge/JudgeServer/model"
+)
+
+// prepareSignature merges the code with the generated driver when the problem is defined by a
+// function signature.
+func (s *SandBox) prepareSignature(problem *model.Problem) error {
+	if problem.Signature == "" {
+		return nil
+	}
+	sig, err := driver.ParseSignature(problem.Signature)
+	if err != nil {
+		return err
+	}
+	code, err := driver.Generate(sig, s.Language, s.Code)
+	if err != nil {
+		return err
+	}
+	s.Code = code
+	return nil
+}
+
+// WriteCases replaces the test data of a signature problem with the typed JSON cases, numbered
+// from 1 in the given order. Every case is encoded before any file is written.
+func WriteCases(sqlExec *db.SqlExec, problem model.Problem, cases []driver.Case) error {
+	if len(cases) == 0 {
+		return errors.Errorf("no test case")
+	}
+	sig, err := driver.ParseSignature(problem.Signature)
+	if err != nil {
+		return err
+	}
+	inputs, outputs := make([]string, len(cases)), make([]string, len(cases))
+	for i, c := range cases {
+		if inputs[i], outputs[i], err = sig.EncodeCase(c); err != nil {
+			return errors.WithMessagef(err, "case %d", i+1)
+		}
+	}
+	proDatas := make([]model.ProblemData, 0, len(cases))
+	for i := range cases {
+		input, output := inputs[i], outputs[i]
+		inputFile, outputFile := TestFiles(int(problem.ID), i+1)
+		if err := os.MkdirAll(filepath.Dir(inputFile), os.ModePerm); err != nil {
+			return errors.WithStack(err)
+		}
+		if err := ioutil.WriteFile(inputFile, []byte(input), os.ModePerm); err != nil {
+			return errors.WithStack(err)
+		}
+		if err := ioutil.WriteFile(outputFile, []byte(output), os.ModePerm); err != nil {
+			return errors.WithStack(err)
+		}
+		proData := model.ProblemData{
+			PID:        int(problem.ID),
+			InputFile:  inputFile,
+			OutputFile: outputFile,
+		}
+		if err := proData.CalculMD5(); err != nil {
+			return err
+		}
+		proDatas = append(proDatas, proData)
+	}
+	return model.ReplaceProblemDatas(sqlExec, int(problem.ID), proDatas)
+}
@@ -0,0 +1,257 @@
+package sandbox
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/easyAation/scaffold/db"
+	"github.com/pkg/errors"
+
+	"online_judge/JudgeServer/common"
+	"online_judge/JudgeServer/compile"
+	"online_judge/JudgeServer/model"
+)
+
+// Judger judges a submission, it is run by the judge queue.
+type Judger interface {
+	Run() (*Result, error)
+	SubmitID() string
+}
+
+// NewJudger returns the judge of the request, SQL queries are judged by SQLJudge.
+func NewJudger(request Request) (Judger, error) {
+	if request.Language == common.SQLLanguage {
+		return &SQLJudge{
+			Request: request,
+		}, nil
+	}
+	return NewSandBox(request)
+}
+
+// SQLJudge judges the query of a SQL problem. Every test runs the sqlite3 shell in the sandbox on
+// a fresh in-memory database: the test input holds the schema and seed data, the output is the
+// result set of the query, one quoted row per line.
+type SQLJudge struct {
+	Request
+}
+
+func (j *SQLJudge) Run() (*Result, error) {
+	sqlExec, err := db.GetSqlExec(context.Background(), "problem")
+	if err != nil {
+		return nil, errors.Wrap(err, "get sqlExec error.")
+	}
+	problem, err := model.GetOneProblem(sqlExec, map[string]interface{}{
+		"id": j.ProblemID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if problem.Type != model.SQLProblem {
+		return nil, errors.Errorf("problem %d is not a SQL problem.", problem.ID)
+	}
+	queryFile := filepath.Join(common.Config.Compile.CodeDir, fmt.Sprintf("%s_%d.sql", j.ID, j.ProblemID))
+	if err := ioutil.WriteFile(queryFile, []byte(j.Code), os.ModePerm); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	problemData, err := model.GetProblemData(sqlExec, map[string]interface{}{
+		"pid": j.ProblemID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if j.Pretest {
+		problemData = pretests(problemData)
+	}
+	results := make([]Result, 0, len(problemData))
+	for index, prodata := range problemData {
+		j.progress(common.Running, index+1)
+		outputFile := filepath.Join(common.Config.SandBox.OutPutDir, fmt.Sprintf("%s_%d", j.ID, index))
+		errorFile := outputFile + ".err"
+		res, err := runQuery(queryFile, prodata.InputFile, outputFile, errorFile, j.TimeLimit, j.MemoryLimit)
+		if err != nil {
+			return nil, err
+		}
+		var result = *res
+		result.Index, result.Subtask = index, prodata.Subtask
+		if result.Code != 0 {
+			result.Status = judge(result.Code, outputFile, prodata)
+		} else {
+			result.Status = CompareResultSets(prodata.OutputFile, outputFile, problem.OrderedResult)
+		}
+		if prodata.IsSample {
+			result.Detail = sampleDetail(prodata, outputFile, errorFile)
+		}
+		if result.Status != common.Accept {
+			result.Kept = keepOutput(j.ID, index, result.Status, prodata, outputFile, errorFile)
+		}
+		results = append(results, result)
+	}
+
+	res := summarize(results)
+	res.Score, res.Subtasks = scoreSubtasks(j.ID, j.ProblemID, res.Cases)
+	if j.Pretest && res.Status == common.Accept {
+		res.Status = common.PretestsPassed
+	}
+	return &res, nil
+}
+
+// runQuery runs the query file on the database seeded by seedFile. The settings and the seed are
+// read from a trusted init file, the query is read from stdin in safe mode, which forbids dot
+// commands and SQL functions touching the file system.
+func runQuery(queryFile, seedFile, outputFile, errorFile string, timeLimit, memoryLimit int64) (*Result, error) {
+	sqlite, err := sqliteExe()
+	if err != nil {
+		return nil, err
+	}
+	seedFile, err = filepath.Abs(seedFile)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	initFile := outputFile + ".init"
+	defer os.Remove(initFile)
+	init := fmt.Sprintf(".bail on\n.headers off\n.mode quote\n.read '%s'\n", seedFile)
+	if err := ioutil.WriteFile(initFile, []byte(init), 0644); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return Exec{
+		ExeFile:     sqlite,
+		InputFile:   queryFile,
+		OutputFile:  outputFile,
+		ErrorFile:   errorFile,
+		Args:        []string{"-safe", "-batch", "-bail", "-init", initFile, ":memory:"},
+		TimeLimit:   timeLimit,
+		MemoryLimit: memoryLimit,
+		Seccomp:     compile.SeccompGeneral,
+	}.Run()
+}
+
+// minSQLiteVersion is the first sqlite3 release with the -safe option.
+var minSQLiteVersion = [3]int{3, 37, 0}
+
+func sqliteExe() (string, error) {
+	if common.Config.SandBox.SQLite != "" {
+		return common.Config.SandBox.SQLite, nil
+	}
+	sqlite, err := exec.LookPath("sqlite3")
+	return sqlite, errors.WithStack(err)
+}
+
+// CheckSQLite checks the sqlite3 shell judging SQL problems supports safe mode, without it every
+// query would fail as a runtime error.
+func CheckSQLite() error {
+	sqlite, err := sqliteExe()
+	if err != nil {
+		return errors.Wrap(err, "sqlite3 not found.")
+	}
+	out, err := exec.Command(sqlite, "-version").Output()
+	if err != nil {
+		return errors.Wrapf(err, "run %s -version fail.", sqlite)
+	}
+	fields := strings.Fields(string(out))
+	if len(fields) == 0 {
+		return errors.Errorf("unknown sqlite3 version.")
+	}
+	var version [3]int
+	if _, err := fmt.Sscanf(fields[0], "%d.%d.%d", &version[0], &version[1], &version[2]); err != nil {
+		return errors.Wrapf(err, "unknown sqlite3 version %s.", fields[0])
+	}
+	for i := range version {
+		if version[i] != minSQLiteVersion[i] {
+			if version[i] < minSQLiteVersion[i] {
+				return errors.Errorf("sqlite3 %s is older than 3.37.0, which is required by SQL problems.", fields[0])
+			}
+			break
+		}
+	}
+	return nil
+}
+
+// CompareResultSets compares the rows of the output with the rows of the answer, the order of
+// the rows only matters when ordered is set.
+func CompareResultSets(answerFile, outputFile string, ordered bool) string {
+	answer, err := resultRows(answerFile)
+	if err != nil {
+		return common.InternalError
+	}
+	output, err := resultRows(outputFile)
+	if err != nil {
+		return common.InternalError
+	}
+	if len(answer) != len(output) {
+		return common.WrongAnswer
+	}
+	if !ordered {
+		sort.Strings(answer)
+		sort.Strings(output)
+	}
+	for i := range answer {
+		if answer[i] != output[i] {
+			return common.WrongAnswer
+		}
+	}
+	return common.Accept
+}
+
+func resultRows(fileName string) ([]string, error) {
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	data = bytes.TrimRight(data, "\r\n")
+	if len(data) == 0 {
+		return []string{}, nil
+	}
+	rows := strings.Split(string(data), "\n")
+	for i := range rows {
+		rows[i] = strings.TrimRight(rows[i], "\r")
+	}
+	return rows, nil
+}
+
+// AnswerSQL writes the answers of the tests of a SQL problem with the result sets of the
+// reference query, the program named main.
+func AnswerSQL(sqlExec *db.SqlExec, problem model.Problem) error {
+	programs, err := NewPrograms(sqlExec, int(problem.ID))
+	if err != nil {
+		return err
+	}
+	reference, ok := programs.Get(ReferenceSolution)
+	if !ok || reference.Language != common.SQLLanguage {
+		return errors.Errorf("reference query %s not found.", ReferenceSolution)
+	}
+	queryFile := filepath.Join(common.Config.Compile.CodeDir, fmt.Sprintf("program_%d_%s.sql", problem.ID, reference.Name))
+	if err := ioutil.WriteFile(queryFile, []byte(reference.Code), os.ModePerm); err != nil {
+		return errors.WithStack(err)
+	}
+	problemData, err := model.GetProblemData(sqlExec, map[string]interface{}{
+		"pid": problem.ID,
+	})
+	if err != nil {
+		return err
+	}
+	for _, proData := range problemData {
+		res, err := runQuery(queryFile, proData.InputFile, proData.OutputFile, "", toolTimeLimit, toolMemoryLimit)
+		if err != nil {
+			return err
+		}
+		if res.Code != 0 {
+			return errors.Errorf("reference query failed on %s: %s", TestName(proData),
+				judge(res.Code, proData.OutputFile, model.ProblemData{}))
+		}
+		if err := proData.CalculMD5(); err != nil {
+			return err
+		}
+		if _, err := model.UpdateProblemDataMD5(sqlExec, proData); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package sandbox
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"online_judge/JudgeServer/common"
+)
+
+func TestCompareResultSets(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	write := func(name, data string) string {
+		file := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(file, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return file
+	}
+	answer := write("answer", "1,'a'\n2,NULL\n")
+	for _, c := range []struct {
+		output  string
+		ordered bool
+		expect  string
+	}{
+		{"1,'a'\n2,NULL\n", true, common.Accept},
+		{"1,'a'\r\n2,NULL", true, common.Accept},
+		{"2,NULL\n1,'a'\n", true, common.WrongAnswer},
+		{"2,NULL\n1,'a'\n", false, common.Accept},
+		{"1,'a'\n", false, common.WrongAnswer},
+		{"1,'a'\n2,'NULL'\n", false, common.WrongAnswer},
+	} {
+		if verdict := CompareResultSets(answer, write("output", c.output), c.ordered); verdict != c.expect {
+			t.Errorf("%q ordered %v: expect %s, but got %s", c.output, c.ordered, c.expect, verdict)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package sandbox
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/easyAation/scaffold/db"
+	"github.com/pkg/errors"
+
+	"online_judge/JudgeServer/common"
+	"online_judge/JudgeServer/model"
+)
+
+const (
+	defaultStressRounds = 100
+	maxStressRounds     = 1000
+)
+
+// StressRequest describes a stress test. Args is the argument template of the generator, the
+// placeholders {seed} and {size} are replaced in every round. Sizes grow from 1 to MaxSize, so the
+// first counterexample found is also the smallest one.
+type StressRequest struct {
+	PID       int    `json:"pid"`
+	Generator string `json:"generator"`
+	Args      string `json:"args"`
+	MaxSize   int    `json:"max_size"`
+	Trusted   string `json:"trusted"`
+	Candidate string `json:"candidate"`
+	Rounds    int    `json:"rounds"`
+}
+
+func (r *StressRequest) Valid() error {
+	if r.PID == 0 {
+		return errors.Errorf("invalid pid")
+	}
+	if r.Generator == "" || r.Trusted == "" || r.Candidate == "" {
+		return errors.Errorf("generator, trusted and candidate are required")
+	}
+	if !strings.Contains(r.Args, "{seed}") {
+		return errors.Errorf("args should contain {seed}")
+	}
+	if r.Rounds <= 0 {
+		r.Rounds = defaultStressRounds
+	}
+	if r.Rounds > maxStressRounds {
+		r.Rounds = maxStressRounds
+	}
+	if r.MaxSize <= 0 {
+		r.MaxSize = 1
+	}
+	return nil
+}
+
+// StressReport is the result of a stress test. When a counterexample is found, Line is the
+// generation script line producing it, InputFile and AnswerFile the saved candidate test with the
+// answer of the trusted solution.
+type StressReport struct {
+	Rounds     int    `json:"rounds"`
+	Found      bool   `json:"found"`
+	Line       string `json:"line,omitempty"`
+	Verdict    string `json:"verdict,omitempty"`
+	InputFile  string `json:"input_file,omitempty"`
+	AnswerFile string `json:"answer_file,omitempty"`
+}
+
+// Stress runs the generator with random seeds and compares the candidate solution with the trusted
+// one, it stops on the first counterexample.
+func Stress(sqlExec *db.SqlExec, problem model.Problem, request StressRequest) (*StressReport, error) {
+	programs, err := NewPrograms(sqlExec, int(problem.ID))
+	if err != nil {
+		return nil, err
+	}
+	if p, ok := programs.Get(request.Generator); !ok || p.Type != model.GeneratorProgram {
+		return nil, errors.Errorf("generator %s not found.", request.Generator)
+	}
+	var execs = make(map[string]Exec)
+	for _, name := range []string{request.Generator, request.Trusted, request.Candidate} {
+		if execs[name], err = programs.Program(name); err != nil {
+			return nil, err
+		}
+	}
+
+	dir := filepath.Join(common.Config.SandBox.ProblemDir, strconv.Itoa(int(problem.ID)), "stress")
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	var (
+		id         = strconv.FormatInt(time.Now().UnixNano(), 10)
+		inputFile  = filepath.Join(dir, id+".in")
+		answerFile = filepath.Join(dir, id+".ans")
+		outputFile = filepath.Join(dir, id+".out")
+		random     = rand.New(rand.NewSource(time.Now().UnixNano()))
+		report     = &StressReport{}
+		keepTest   = false
+	)
+	defer func() {
+		os.Remove(outputFile)
+		if !keepTest {
+			os.Remove(inputFile)
+			os.Remove(answerFile)
+		}
+	}()
+
+	for round := 0; round < request.Rounds; round++ {
+		size := 1 + round*request.MaxSize/request.Rounds
+		args := strings.NewReplacer(
+			"{seed}", strconv.FormatInt(random.Int63(), 10),
+			"{size}", strconv.Itoa(size),
+		).Replace(request.Args)
+		report.Rounds = round + 1
+
+		if err := runTool(execs[request.Generator], os.DevNull, inputFile, strings.Fields(args)); err != nil {
+			return nil, errors.WithMessage(err, "generator failed")
+		}
+		if err := programs.Validate(inputFile); err != nil {
+			return nil, err
+		}
+		if err := runTool(execs[request.Trusted], inputFile, answerFile, nil); err != nil {
+			return nil, errors.WithMessage(err, "trusted solution failed")
+		}
+		candidate := execs[request.Candidate]
+		candidate.InputFile, candidate.OutputFile = inputFile, outputFile
+		candidate.TimeLimit, candidate.MemoryLimit = problem.TimeLimit, problem.MemoryLimit
+		res, err := candidate.Run()
+		if err != nil {
+			return nil, err
+		}
+		var verdict string
+		if res.Code != 0 {
+			verdict = judge(res.Code, outputFile, model.ProblemData{})
+		} else {
+			verdict = Compare(answerFile, outputFile)
+		}
+		if verdict != common.Accept {
+			keepTest = true
+			report.Found = true
+			report.Verdict = verdict
+			report.InputFile, report.AnswerFile = inputFile, answerFile
+			report.Line = fmt.Sprintf("%s %s", request.Generator, args)
+			break
+		}
+	}
+	return report, nil
+}
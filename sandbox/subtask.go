@@ -0,0 +1,51 @@
+package sandbox
+
+import (
+	"sort"
+
+	"online_judge/JudgeServer/common"
+	"online_judge/JudgeServer/model"
+)
+
+// scoreSubtasks scores the case results of a judged problem, every test is worth the same. The
+// tests of a subtask only score when all of them are accepted, the tests without subtask (0) score
+// one by one. It returns the total score and the score of each subtask, in percentage.
+func scoreSubtasks(submitID string, pid int, cases []Result) (float64, []model.SubmitSubtask) {
+	if len(cases) == 0 {
+		return 0, nil
+	}
+	var (
+		weight = 100 / float64(len(cases))
+		passed = make(map[int]float64)
+		failed = make(map[int]bool)
+	)
+	for _, c := range cases {
+		score := passed[c.Subtask]
+		if c.Status == common.Accept {
+			score += weight
+		} else {
+			failed[c.Subtask] = true
+		}
+		passed[c.Subtask] = score
+	}
+	var (
+		total    float64
+		subtasks = make([]model.SubmitSubtask, 0, len(passed))
+	)
+	for subtask, score := range passed {
+		if subtask != 0 && failed[subtask] {
+			score = 0
+		}
+		total += score
+		subtasks = append(subtasks, model.SubmitSubtask{
+			SubmitID: submitID,
+			PID:      pid,
+			Subtask:  subtask,
+			Score:    score,
+		})
+	}
+	sort.Slice(subtasks, func(i, j int) bool {
+		return subtasks[i].Subtask < subtasks[j].Subtask
+	})
+	return total, subtasks
+}
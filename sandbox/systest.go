@@ -0,0 +1,83 @@
+package sandbox
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/easyAation/scaffold/db"
+
+	"online_judge/JudgeServer/common"
+	"online_judge/JudgeServer/model"
+	"online_judge/JudgeServer/stream"
+)
+
+// SystemTest judges the last pretest passed submission of every contestant and problem on the
+// full test set, earlier pretest passed submissions are skipped.
+func SystemTest(sqlExec *db.SqlExec, contest model.Contest) ([]VerdictChange, error) {
+	submits, err := model.GetContestSubmit(sqlExec, map[string]interface{}{
+		"cid":    contest.ID,
+		"result": common.PretestsPassed,
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(submits, func(i, j int) bool {
+		return submits[i].ID < submits[j].ID
+	})
+	last := make(map[string]int64)
+	for _, submit := range submits {
+		last[fmt.Sprintf("%s_%d", submit.UID, submit.PID)] = submit.ID
+	}
+
+	var (
+		changes = make([]VerdictChange, 0, len(submits))
+		cache   = newProblemCache(sqlExec)
+	)
+	for _, submit := range submits {
+		change := VerdictChange{
+			SubmitID: submit.SubmitID,
+			UID:      submit.UID,
+			PID:      submit.PID,
+			CID:      submit.CID,
+			Old:      submit.Result,
+			New:      common.Skipped,
+		}
+		values := map[string]interface{}{
+			"result": common.Skipped,
+		}
+		if last[fmt.Sprintf("%s_%d", submit.UID, submit.PID)] == submit.ID {
+			problem, err := cache.get(submit.PID)
+			if err != nil {
+				return nil, err
+			}
+			res, err := Rejudge(submit.Submit, *problem, false)
+			if err != nil {
+				return nil, err
+			}
+			if err := model.ReplaceSubmitOutputs(sqlExec, submit.SubmitID, res.KeptOutputs()); err != nil {
+				return nil, err
+			}
+			if problem.Type == model.OptimizationProblem {
+				if err := model.SaveSubmitScores(sqlExec, submit.SubmitID, res.RawScores()); err != nil {
+					return nil, err
+				}
+			}
+			if len(res.Subtasks) != 0 {
+				if err := model.SaveSubmitSubtasks(sqlExec, submit.SubmitID, res.Subtasks); err != nil {
+					return nil, err
+				}
+			}
+			change.New = res.Status
+			values["result"] = res.Status
+			values["score"] = res.Score
+			values["run_time"] = res.Time
+			values["memory"] = res.Memory
+		}
+		if _, err := model.UpdateContestSubmit(sqlExec, submit.ID, values); err != nil {
+			return nil, err
+		}
+		changes = append(changes, change)
+	}
+	stream.ContestChanged(contest.ID)
+	return changes, nil
+}
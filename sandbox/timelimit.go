@@ -0,0 +1,132 @@
+package sandbox
+
+import (
+	"sort"
+
+	"github.com/easyAation/scaffold/db"
+
+	"online_judge/JudgeServer/common"
+	"online_judge/JudgeServer/model"
+)
+
+// default values of common.PrepareConfig.
+const (
+	defaultTimeLimitFactor = 2.5
+	defaultTimeLimitMargin = 0.3
+	// suggested time limits are rounded up to a multiple of timeLimitStep ms.
+	timeLimitStep = 100
+)
+
+// TimeLimitSuggestion is the proposed time limit of one language.
+type TimeLimitSuggestion struct {
+	Language  string `json:"language"`
+	Solution  string `json:"solution"`
+	Slowest   int64  `json:"slowest"`
+	TimeLimit int64  `json:"time_limit"`
+}
+
+// TightTest is a test where the reference solution is close to the current time limit.
+type TightTest struct {
+	Index     int   `json:"index"`
+	Time      int64 `json:"time"`
+	TimeLimit int64 `json:"time_limit"`
+}
+
+// TimeLimitReport is the result of SuggestTimeLimit.
+type TimeLimitReport struct {
+	Suggestions []TimeLimitSuggestion `json:"suggestions"`
+	TightTests  []TightTest           `json:"tight_tests"`
+	// Failed lists the correct solutions that did not pass even with the relaxed limit.
+	Failed []SolutionReport `json:"failed"`
+}
+
+// SuggestTimeLimit runs the reference and the AC tagged solutions with a relaxed time limit and
+// proposes a time limit per language based on the slowest correct solution.
+func SuggestTimeLimit(sqlExec *db.SqlExec, problem model.Problem) (*TimeLimitReport, error) {
+	solutions, err := model.GetProblemPrograms(sqlExec, map[string]interface{}{
+		"pid":  problem.ID,
+		"type": model.SolutionProgram,
+	})
+	if err != nil {
+		return nil, err
+	}
+	relaxed := problem
+	relaxed.TimeLimit = toolTimeLimit
+
+	var (
+		report = &TimeLimitReport{
+			Suggestions: make([]TimeLimitSuggestion, 0),
+			TightTests:  make([]TightTest, 0),
+			Failed:      make([]SolutionReport, 0),
+		}
+		slowest = make(map[string]*TimeLimitSuggestion)
+	)
+	for _, solution := range solutions {
+		if solution.Tag != model.TagAccept && solution.Name != ReferenceSolution {
+			continue
+		}
+		res, err := checkSolution(relaxed, solution)
+		if err != nil {
+			return nil, err
+		}
+		if !res.Matched {
+			report.Failed = append(report.Failed, *res)
+			continue
+		}
+		var max int64
+		for _, c := range res.Cases {
+			if c.Time > max {
+				max = c.Time
+			}
+		}
+		if s, ok := slowest[solution.Language]; !ok || s.Slowest < max {
+			slowest[solution.Language] = &TimeLimitSuggestion{
+				Language: solution.Language,
+				Solution: solution.Name,
+				Slowest:  max,
+			}
+		}
+		if solution.Name == ReferenceSolution {
+			report.TightTests = tightTests(res.Cases, problem.TimeLimit)
+		}
+	}
+
+	for _, s := range slowest {
+		s.TimeLimit = suggestedLimit(s.Slowest)
+		report.Suggestions = append(report.Suggestions, *s)
+	}
+	sort.Slice(report.Suggestions, func(i, j int) bool {
+		return report.Suggestions[i].Language < report.Suggestions[j].Language
+	})
+	return report, nil
+}
+
+func suggestedLimit(slowest int64) int64 {
+	factor := common.Config.Prepare.TimeLimitFactor
+	if factor <= 0 {
+		factor = defaultTimeLimitFactor
+	}
+	limit := int64(float64(slowest) * factor)
+	if limit%timeLimitStep != 0 || limit == 0 {
+		limit = (limit/timeLimitStep + 1) * timeLimitStep
+	}
+	return limit
+}
+
+func tightTests(cases []Result, timeLimit int64) []TightTest {
+	margin := common.Config.Prepare.TimeLimitMargin
+	if margin <= 0 {
+		margin = defaultTimeLimitMargin
+	}
+	tests := make([]TightTest, 0)
+	for _, c := range cases {
+		if float64(c.Time) > float64(timeLimit)*(1-margin) {
+			tests = append(tests, TightTest{
+				Index:     c.Index,
+				Time:      c.Time,
+				TimeLimit: timeLimit,
+			})
+		}
+	}
+	return tests
+}
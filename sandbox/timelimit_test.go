@@ -0,0 +1,13 @@
+package sandbox
+
+import (
+	"testing"
+)
+
+func TestSuggestedLimit(t *testing.T) {
+	for slowest, expect := range map[int64]int64{0: 100, 40: 100, 120: 300, 400: 1000} {
+		if limit := suggestedLimit(slowest); limit != expect {
+			t.Errorf("slowest %d: expect %d, but result is %d", slowest, expect, limit)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package scoreboard
+
+import (
+	"sort"
+	"time"
+
+	"github.com/easyAation/scaffold/db"
+
+	"online_judge/JudgeServer/common"
+	"online_judge/JudgeServer/model"
+)
+
+// default values of common.ScoreboardConfig.
+const defaultPenaltyMinutes = 20
+
+type ICPCCell struct {
+	PID int `json:"pid"`
+	// Attempts counts the rejected attempts and the first accepted one.
+	Attempts int  `json:"attempts"`
+	Solved   bool `json:"solved"`
+	// SolvedAt is the minutes from the start to the first accepted attempt.
+	SolvedAt   int  `json:"solved_at"`
+	FirstBlood bool `json:"first_blood"`
+	// Pending counts the attempts not judged yet, or judged after the freeze.
+	Pending int `json:"pending"`
+}
+
+type ICPCRow struct {
+	Rank    int    `json:"rank"`
+	UID     string `json:"uid"`
+	Name    string `json:"name"`
+	Solved  int    `json:"solved"`
+	Penalty int    `json:"penalty"`
+	// LastAC is the minutes of the last accepted problem, the earlier wins a tie.
+	LastAC int        `json:"last_ac"`
+	Cells  []ICPCCell `json:"cells"`
+}
+
+// verdicts not caused by the contestant, they are neither accepted nor rejected.
+var unjudged = map[string]bool{
+	common.Running:       true,
+	common.SysteamError:  true,
+	common.InternalError: true,
+	common.Skipped:       true,
+	common.Pending:       true,
+}
+
+func accepted(result string) bool {
+	// pretests passed counts as accepted until the system test.
+	return result == common.Accept || result == common.PretestsPassed
+}
+
+// PenaltyMinutes returns the penalty of a rejected attempt.
+func PenaltyMinutes() int {
+	if common.Config.Scoreboard.PenaltyMinutes <= 0 {
+		return defaultPenaltyMinutes
+	}
+	return common.Config.Scoreboard.PenaltyMinutes
+}
+
+// ICPC computes the ICPC standings of the contest problems, pids in display order. The rank is
+// the number of solved problems, then the penalty: minutes from the start to the first accepted
+// attempt plus the penalty of every prior rejected attempt, then the earlier last accepted time.
+// When frozen, the attempts from the freeze time are pending.
+func ICPC(contest model.Contest, pids []int, submits []model.ContestSubmit, names map[string]string,
+	frozen bool) []ICPCRow {
+	freezeAt := contest.FreezeAt()
+	frozen = frozen && !freezeAt.IsZero()
+	sort.SliceStable(submits, func(i, j int) bool {
+		if !submits[i].CreatedAT.Equal(submits[j].CreatedAT) {
+			return submits[i].CreatedAT.Before(submits[j].CreatedAT)
+		}
+		return submits[i].ID < submits[j].ID
+	})
+	position := make(map[int]int, len(pids))
+	for i, pid := range pids {
+		position[pid] = i
+	}
+
+	var (
+		rows       = make(map[string]*ICPCRow)
+		firstBlood = make(map[int]bool)
+	)
+	for _, submit := range submits {
+		index, ok := position[submit.PID]
+		if !ok || submit.Upsolve {
+			continue
+		}
+		row, ok := rows[submit.UID]
+		if !ok {
+			row = &ICPCRow{
+				UID:   submit.UID,
+				Name:  names[submit.UID],
+				Cells: make([]ICPCCell, len(pids)),
+			}
+			for i, pid := range pids {
+				row.Cells[i].PID = pid
+			}
+			rows[submit.UID] = row
+		}
+		cell := &row.Cells[index]
+		switch {
+		case cell.Solved:
+		case unjudged[submit.Result] || frozen && !submit.CreatedAT.Before(freezeAt):
+			cell.Pending++
+		case accepted(submit.Result):
+			cell.Attempts++
+			cell.Solved = true
+			cell.SolvedAt = int(submit.CreatedAT.Sub(contest.StartAt) / time.Minute)
+			if !firstBlood[submit.PID] {
+				firstBlood[submit.PID] = true
+				cell.FirstBlood = true
+			}
+		case submit.Result == common.CompileError && !common.Config.Scoreboard.PenaltyCompileError:
+		default:
+			cell.Attempts++
+		}
+	}
+
+	standings := make([]ICPCRow, 0, len(rows))
+	for _, row := range rows {
+		row.recount()
+		standings = append(standings, *row)
+	}
+	rank(standings)
+	return standings
+}
+
+// recount computes the solved count, the penalty and the last accepted time from the cells.
+func (row *ICPCRow) recount() {
+	row.Solved, row.Penalty, row.LastAC = 0, 0, 0
+	for _, cell := range row.Cells {
+		if !cell.Solved {
+			continue
+		}
+		row.Solved++
+		row.Penalty += cell.SolvedAt + (cell.Attempts-1)*PenaltyMinutes()
+		if cell.SolvedAt > row.LastAC {
+			row.LastAC = cell.SolvedAt
+		}
+	}
+}
+
+// rank sorts the rows and sets their rank, tied rows share the rank.
+func rank(standings []ICPCRow) {
+	sort.Slice(standings, func(i, j int) bool {
+		a, b := standings[i], standings[j]
+		if !tied(a, b) {
+			return before(a, b)
+		}
+		return a.UID < b.UID
+	})
+	for i := range standings {
+		standings[i].Rank = i + 1
+		if i > 0 && tied(standings[i], standings[i-1]) {
+			standings[i].Rank = standings[i-1].Rank
+		}
+	}
+}
+
+func tied(a, b ICPCRow) bool {
+	return a.Solved == b.Solved && a.Penalty == b.Penalty && a.LastAC == b.LastAC
+}
+
+func before(a, b ICPCRow) bool {
+	if a.Solved != b.Solved {
+		return a.Solved > b.Solved
+	}
+	if a.Penalty != b.Penalty {
+		return a.Penalty < b.Penalty
+	}
+	return a.LastAC < b.LastAC
+}
+
+// ContestPIDs returns the problems of the contest in display order.
+func ContestPIDs(sqlExec *db.SqlExec, cid int64) ([]int, error) {
+	cps, err := model.GetContestProblems(sqlExec, map[string]interface{}{
+		"cid": cid,
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(cps, func(i, j int) bool {
+		return cps[i].Position < cps[j].Position
+	})
+	pids := make([]int, 0, len(cps))
+	for _, cp := range cps {
+		pids = append(pids, int(cp.PID))
+	}
+	return pids, nil
+}
+
+// LoadICPC loads the submissions of the contest and computes its ICPC standings.
+func LoadICPC(sqlExec *db.SqlExec, contest model.Contest, names map[string]string, frozen bool) ([]ICPCRow, error) {
+	pids, err := ContestPIDs(sqlExec, contest.ID)
+	if err != nil {
+		return nil, err
+	}
+	submits, err := model.GetContestSubmit(sqlExec, map[string]interface{}{
+		"cid": contest.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ICPC(contest, pids, submits, names, frozen), nil
+}
@@ -0,0 +1,96 @@
+package scoreboard
+
+import (
+	"testing"
+	"time"
+
+	"online_judge/JudgeServer/common"
+	"online_judge/JudgeServer/model"
+)
+
+func TestICPC(t *testing.T) {
+	start := time.Date(2020, 1, 1, 8, 0, 0, 0, time.UTC)
+	contest := model.Contest{StartAt: start, EndAt: start.Add(5 * time.Hour)}
+	var id int64
+	submit := func(uid string, pid, minute int, result string) model.ContestSubmit {
+		id++
+		return model.ContestSubmit{Submit: model.Submit{
+			ID:        id,
+			UID:       uid,
+			PID:       pid,
+			Result:    result,
+			CreatedAT: start.Add(time.Duration(minute) * time.Minute),
+		}}
+	}
+	submits := []model.ContestSubmit{
+		submit("a", 1, 10, common.WrongAnswer),
+		submit("a", 1, 20, common.CompileError),
+		submit("a", 1, 30, common.Accept),
+		submit("a", 1, 40, common.WrongAnswer),
+		submit("b", 1, 15, common.Accept),
+		submit("b", 2, 50, common.Accept),
+		submit("c", 2, 60, common.TimeLimit),
+		submit("d", 1, 15, common.Accept),
+		submit("d", 2, 50, common.Accept),
+	}
+	submits = append(submits, submit("c", 1, 5, common.Accept))
+	submits[len(submits)-1].Upsolve = true
+
+	standings := ICPC(contest, []int{1, 2}, submits, map[string]string{"a": "Alice"}, false)
+	if len(standings) != 4 {
+		t.Fatalf("expect 4 rows, but got %d", len(standings))
+	}
+	for i, expect := range []struct {
+		uid              string
+		rank, solved, pn int
+	}{
+		{"b", 1, 2, 65},
+		{"d", 1, 2, 65},
+		{"a", 3, 1, 50},
+		{"c", 4, 0, 0},
+	} {
+		row := standings[i]
+		if row.UID != expect.uid || row.Rank != expect.rank || row.Solved != expect.solved || row.Penalty != expect.pn {
+			t.Errorf("row %d: expect %+v, but got %+v", i, expect, row)
+		}
+	}
+	a := standings[2]
+	if a.Name != "Alice" || a.Cells[0].Attempts != 2 || a.Cells[0].SolvedAt != 30 || a.Cells[0].FirstBlood {
+		t.Errorf("unexpected cell of a: %+v", a.Cells[0])
+	}
+	if !standings[0].Cells[0].FirstBlood || standings[1].Cells[0].FirstBlood {
+		t.Errorf("expect first blood of problem 1 on b only")
+	}
+	if standings[3].Cells[1].Attempts != 1 || standings[3].Cells[0].Attempts != 0 {
+		t.Errorf("unexpected cells of c: %+v", standings[3].Cells)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	start := time.Date(2020, 1, 1, 8, 0, 0, 0, time.UTC)
+	contest := model.Contest{StartAt: start, EndAt: start.Add(5 * time.Hour), FreezeMinutes: 60}
+	submits := []model.ContestSubmit{
+		{Submit: model.Submit{ID: 1, UID: "a", PID: 1, Result: common.Accept, CreatedAT: start.Add(30 * time.Minute)}},
+		{Submit: model.Submit{ID: 2, UID: "b", PID: 1, Result: common.Accept, CreatedAT: start.Add(250 * time.Minute)}},
+		{Submit: model.Submit{ID: 3, UID: "b", PID: 2, Result: common.Accept, CreatedAT: start.Add(260 * time.Minute)}},
+		{Submit: model.Submit{ID: 4, UID: "a", PID: 2, Result: common.WrongAnswer, CreatedAT: start.Add(270 * time.Minute)}},
+	}
+	frozen := ICPC(contest, []int{1, 2}, submits, nil, true)
+	final := ICPC(contest, []int{1, 2}, submits, nil, false)
+	if frozen[0].UID != "a" || frozen[1].Cells[0].Pending != 1 {
+		t.Fatalf("unexpected frozen standings: %+v", frozen)
+	}
+	steps := Resolve(frozen, final)
+	if len(steps) != 3 {
+		t.Fatalf("expect 3 steps, but got %+v", steps)
+	}
+	if s := steps[0]; s.UID != "b" || s.PID != 1 || !s.Solved || s.From != 2 || s.To != 2 {
+		t.Errorf("unexpected first step: %+v", s)
+	}
+	if s := steps[1]; s.UID != "b" || s.PID != 2 || s.From != 2 || s.To != 1 {
+		t.Errorf("unexpected second step: %+v", s)
+	}
+	if s := steps[2]; s.UID != "a" || s.Solved || s.From != 2 || s.To != 2 {
+		t.Errorf("unexpected last step: %+v", s)
+	}
+}
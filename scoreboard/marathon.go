@@ -0,0 +1,116 @@
+package scoreboard
+
+import (
+	"github.com/easyAation/scaffold/db"
+
+	"online_judge/JudgeServer/model"
+)
+
+// Points returns the points of a raw score in percentage of the best raw score of the test.
+func Points(objective string, raw, best float64) float64 {
+	if raw == best {
+		return 100
+	}
+	var points float64
+	switch objective {
+	case model.Maximize:
+		if best > 0 {
+			points = raw / best * 100
+		}
+	case model.Minimize:
+		if raw > 0 && best > 0 {
+			points = best / raw * 100
+		}
+	}
+	if points < 0 {
+		return 0
+	}
+	if points > 100 {
+		return 100
+	}
+	return points
+}
+
+func better(objective string, a, b float64) bool {
+	if objective == model.Minimize {
+		return a < b
+	}
+	return a > b
+}
+
+// Marathon computes the marathon standings of the contest. The points of a test are relative to
+// the best raw score achieved on it in the contest, so they are computed again on every call as
+// the bests improve. A submission scores the average points of the tests it was judged on, the
+// pretests during the contest. A contestant scores the best submission of each problem.
+func Marathon(sqlExec *db.SqlExec, contest model.Contest, names map[string]string) ([]ScoreRow, error) {
+	cps, err := model.GetContestProblems(sqlExec, map[string]interface{}{
+		"cid": contest.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	submits, err := model.GetContestSubmit(sqlExec, map[string]interface{}{
+		"cid": contest.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	owners := make(map[string]string, len(submits))
+	for _, submit := range submits {
+		if !submit.Upsolve {
+			owners[submit.SubmitID] = submit.UID
+		}
+	}
+
+	rows := make(map[string]*ScoreRow)
+	for _, cp := range cps {
+		problem, err := model.GetOneProblem(sqlExec, map[string]interface{}{
+			"id": cp.PID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if problem.Type != model.OptimizationProblem {
+			continue
+		}
+		scores, err := model.GetSubmitScores(sqlExec, map[string]interface{}{
+			"pid": cp.PID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		var (
+			judged = make(map[string]int)
+			raws   = make(map[string]map[int]float64)
+			best   = make(map[int]float64)
+		)
+		for _, score := range scores {
+			if _, ok := owners[score.SubmitID]; !ok {
+				continue
+			}
+			judged[score.SubmitID]++
+			if !score.Accepted {
+				continue
+			}
+			if raws[score.SubmitID] == nil {
+				raws[score.SubmitID] = make(map[int]float64)
+			}
+			raws[score.SubmitID][score.DataID] = score.Raw
+			if b, ok := best[score.DataID]; !ok || better(problem.Objective, score.Raw, b) {
+				best[score.DataID] = score.Raw
+			}
+		}
+		for submitID, tests := range judged {
+			var total float64
+			for id, raw := range raws[submitID] {
+				total += Points(problem.Objective, raw, best[id])
+			}
+			score := total / float64(tests)
+			p := scoreRow(rows, owners[submitID], names).problem(int(cp.PID))
+			if p.SubmitID == "" || score > p.Score {
+				p.Score, p.SubmitID = score, submitID
+			}
+		}
+	}
+	return rankScores(rows), nil
+}
@@ -0,0 +1,27 @@
+package scoreboard
+
+import (
+	"testing"
+
+	"online_judge/JudgeServer/model"
+)
+
+func TestPoints(t *testing.T) {
+	for _, c := range []struct {
+		objective      string
+		raw, best, out float64
+	}{
+		{model.Maximize, 50, 100, 50},
+		{model.Maximize, 100, 100, 100},
+		{model.Maximize, -5, 100, 0},
+		{model.Maximize, 0, 0, 100},
+		{model.Minimize, 200, 100, 50},
+		{model.Minimize, 100, 100, 100},
+		{model.Minimize, 0, 0, 100},
+		{model.Minimize, 10, 0, 0},
+	} {
+		if points := Points(c.objective, c.raw, c.best); points != c.out {
+			t.Errorf("%s raw %v best %v: expect %v, but got %v", c.objective, c.raw, c.best, c.out, points)
+		}
+	}
+}
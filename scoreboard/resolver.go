@@ -0,0 +1,72 @@
+package scoreboard
+
+// RevealStep reveals the pending cell of a problem of a contestant, in the order of a resolver
+// ceremony.
+type RevealStep struct {
+	UID      string `json:"uid"`
+	PID      int    `json:"pid"`
+	Solved   bool   `json:"solved"`
+	Attempts int    `json:"attempts"`
+	SolvedAt int    `json:"solved_at"`
+	// rank of the contestant before and after the reveal.
+	From int `json:"from"`
+	To   int `json:"to"`
+}
+
+// Resolve returns the reveal sequence from the frozen standings to the final ones. The lowest
+// ranked contestant with pending cells reveals the first of them, until no cell is pending.
+func Resolve(frozen, final []ICPCRow) []RevealStep {
+	finals := make(map[string]ICPCRow, len(final))
+	for _, row := range final {
+		finals[row.UID] = row
+	}
+	standings := make([]ICPCRow, len(frozen))
+	for i, row := range frozen {
+		standings[i] = row
+		standings[i].Cells = append([]ICPCCell{}, row.Cells...)
+	}
+	rank(standings)
+
+	steps := make([]RevealStep, 0)
+	for {
+		i := len(standings) - 1
+		for ; i >= 0 && !pending(standings[i]); i-- {
+		}
+		if i < 0 {
+			return steps
+		}
+		row := &standings[i]
+		c := 0
+		for ; row.Cells[c].Pending == 0; c++ {
+		}
+		cell := finals[row.UID].Cells[c]
+		cell.Pending = 0
+		row.Cells[c] = cell
+		row.recount()
+		uid, from := row.UID, row.Rank
+		rank(standings)
+		step := RevealStep{
+			UID:      uid,
+			PID:      cell.PID,
+			Solved:   cell.Solved,
+			Attempts: cell.Attempts,
+			SolvedAt: cell.SolvedAt,
+			From:     from,
+		}
+		for _, r := range standings {
+			if r.UID == uid {
+				step.To = r.Rank
+			}
+		}
+		steps = append(steps, step)
+	}
+}
+
+func pending(row ICPCRow) bool {
+	for _, cell := range row.Cells {
+		if cell.Pending != 0 {
+			return true
+		}
+	}
+	return false
+}
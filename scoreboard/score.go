@@ -0,0 +1,174 @@
+package scoreboard
+
+import (
+	"sort"
+
+	"github.com/easyAation/scaffold/db"
+
+	"online_judge/JudgeServer/model"
+)
+
+type ScoreProblem struct {
+	PID      int     `json:"pid"`
+	SubmitID string  `json:"submit_id"`
+	Score    float64 `json:"score"`
+}
+
+// ScoreRow is a row of the score based standings: marathon, OI and IOI.
+type ScoreRow struct {
+	Rank     int            `json:"rank"`
+	UID      string         `json:"uid"`
+	Name     string         `json:"name"`
+	Score    float64        `json:"score"`
+	Problems []ScoreProblem `json:"problems"`
+}
+
+func scoreRow(rows map[string]*ScoreRow, uid string, names map[string]string) *ScoreRow {
+	row, ok := rows[uid]
+	if !ok {
+		row = &ScoreRow{
+			UID:      uid,
+			Name:     names[uid],
+			Problems: make([]ScoreProblem, 0),
+		}
+		rows[uid] = row
+	}
+	return row
+}
+
+// problem returns the problem of the row, it is added if missing.
+func (row *ScoreRow) problem(pid int) *ScoreProblem {
+	for i := range row.Problems {
+		if row.Problems[i].PID == pid {
+			return &row.Problems[i]
+		}
+	}
+	row.Problems = append(row.Problems, ScoreProblem{PID: pid})
+	return &row.Problems[len(row.Problems)-1]
+}
+
+// rankScores sums the scores of the rows and ranks them, rows with equal scores share the rank.
+func rankScores(rows map[string]*ScoreRow) []ScoreRow {
+	standings := make([]ScoreRow, 0, len(rows))
+	for _, row := range rows {
+		row.Score = 0
+		for _, p := range row.Problems {
+			row.Score += p.Score
+		}
+		standings = append(standings, *row)
+	}
+	sort.Slice(standings, func(i, j int) bool {
+		if standings[i].Score != standings[j].Score {
+			return standings[i].Score > standings[j].Score
+		}
+		return standings[i].UID < standings[j].UID
+	})
+	for i := range standings {
+		standings[i].Rank = i + 1
+		if i > 0 && standings[i].Score == standings[i-1].Score {
+			standings[i].Rank = standings[i-1].Rank
+		}
+	}
+	return standings
+}
+
+// OI computes the OI standings: the last judged submission of each problem counts.
+func OI(submits []model.ContestSubmit, names map[string]string) []ScoreRow {
+	sort.Slice(submits, func(i, j int) bool {
+		return submits[i].ID < submits[j].ID
+	})
+	rows := make(map[string]*ScoreRow)
+	for _, submit := range submits {
+		if submit.Upsolve || unjudged[submit.Result] {
+			continue
+		}
+		p := scoreRow(rows, submit.UID, names).problem(submit.PID)
+		p.Score, p.SubmitID = submit.Score, submit.SubmitID
+	}
+	return rankScores(rows)
+}
+
+// IOI computes the IOI standings: the score of a problem is the sum of the best score of each of
+// its subtasks over all submissions. Submissions without subtask scores count as a single subtask.
+func IOI(submits []model.ContestSubmit, subtasks map[string][]model.SubmitSubtask,
+	names map[string]string) []ScoreRow {
+	sort.Slice(submits, func(i, j int) bool {
+		return submits[i].ID < submits[j].ID
+	})
+	var (
+		rows = make(map[string]*ScoreRow)
+		best = make(map[string]map[int]map[int]float64)
+	)
+	for _, submit := range submits {
+		if submit.Upsolve || unjudged[submit.Result] {
+			continue
+		}
+		parts, ok := subtasks[submit.SubmitID]
+		if !ok {
+			parts = []model.SubmitSubtask{{Score: submit.Score}}
+		}
+		p := scoreRow(rows, submit.UID, names).problem(submit.PID)
+		if best[submit.UID] == nil {
+			best[submit.UID] = make(map[int]map[int]float64)
+		}
+		problem := best[submit.UID][submit.PID]
+		if problem == nil {
+			problem = make(map[int]float64)
+			best[submit.UID][submit.PID] = problem
+		}
+		improved := p.SubmitID == ""
+		for _, part := range parts {
+			if score, ok := problem[part.Subtask]; !ok || part.Score > score {
+				problem[part.Subtask] = part.Score
+				improved = improved || part.Score > score
+			}
+		}
+		p.Score = 0
+		for _, score := range problem {
+			p.Score += score
+		}
+		if improved {
+			p.SubmitID = submit.SubmitID
+		}
+	}
+	return rankScores(rows)
+}
+
+// LoadScores loads the submissions of the OI or IOI contest and computes its standings.
+func LoadScores(sqlExec *db.SqlExec, contest model.Contest, names map[string]string) ([]ScoreRow, error) {
+	submits, err := model.GetContestSubmit(sqlExec, map[string]interface{}{
+		"cid": contest.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return Scores(sqlExec, contest, submits, names)
+}
+
+// Scores computes the standings of the OI or IOI contest from the submissions, loading the
+// subtask scores for IOI.
+func Scores(sqlExec *db.SqlExec, contest model.Contest, submits []model.ContestSubmit,
+	names map[string]string) ([]ScoreRow, error) {
+	if contest.Mode == model.OIMode {
+		return OI(submits, names), nil
+	}
+	cps, err := model.GetContestProblems(sqlExec, map[string]interface{}{
+		"cid": contest.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	subtasks := make(map[string][]model.SubmitSubtask)
+	for _, cp := range cps {
+		list, err := model.GetSubmitSubtasks(sqlExec, map[string]interface{}{
+			"pid": cp.PID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, subtask := range list {
+			subtasks[subtask.SubmitID] = append(subtasks[subtask.SubmitID], subtask)
+		}
+	}
+	return IOI(submits, subtasks, names), nil
+}
@@ -0,0 +1,44 @@
+package scoreboard
+
+import (
+	"testing"
+
+	"online_judge/JudgeServer/common"
+	"online_judge/JudgeServer/model"
+)
+
+func TestOIAndIOI(t *testing.T) {
+	submit := func(id int64, uid string, pid int, result string, score float64) model.ContestSubmit {
+		return model.ContestSubmit{Submit: model.Submit{
+			ID:       id,
+			UID:      uid,
+			PID:      pid,
+			SubmitID: string(rune('a'+id)) + uid,
+			Result:   result,
+			Score:    score,
+		}}
+	}
+	submits := []model.ContestSubmit{
+		submit(1, "a", 1, common.WrongAnswer, 60),
+		submit(2, "a", 1, common.WrongAnswer, 40),
+		submit(3, "a", 1, common.Running, 0),
+		submit(4, "b", 1, common.Accept, 100),
+		submit(5, "b", 2, common.WrongAnswer, 30),
+	}
+	oi := OI(submits, nil)
+	if len(oi) != 2 || oi[0].UID != "b" || oi[0].Score != 130 || oi[1].Score != 40 {
+		t.Errorf("unexpected OI standings: %+v", oi)
+	}
+
+	subtasks := map[string][]model.SubmitSubtask{
+		submits[0].SubmitID: {{Subtask: 1, Score: 60}, {Subtask: 2, Score: 0}},
+		submits[1].SubmitID: {{Subtask: 1, Score: 0}, {Subtask: 2, Score: 40}},
+	}
+	ioi := IOI(submits, subtasks, nil)
+	if len(ioi) != 2 || ioi[0].UID != "b" || ioi[0].Score != 130 || ioi[1].Score != 100 || ioi[1].Rank != 2 {
+		t.Errorf("unexpected IOI standings: %+v", ioi)
+	}
+	if p := ioi[1].Problems[0]; p.SubmitID != submits[1].SubmitID {
+		t.Errorf("expect the last improving submission, but got %+v", p)
+	}
+}
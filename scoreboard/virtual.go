@@ -0,0 +1,36 @@
+package scoreboard
+
+import (
+	"time"
+
+	"online_judge/JudgeServer/model"
+)
+
+// VirtualSubmits returns the submissions ranked in the virtual standings at now: the contest
+// submissions up to the elapsed time of the virtual participation, and the submissions of the
+// virtual participation moved to the same time of the contest.
+func VirtualSubmits(contest model.Contest, v model.VirtualParticipation, submits []model.ContestSubmit,
+	now time.Time) []model.ContestSubmit {
+	until := v.ContestTime(contest, now)
+	if until.After(contest.EndAt) {
+		until = contest.EndAt
+	}
+	list := make([]model.ContestSubmit, 0, len(submits))
+	for _, submit := range submits {
+		switch {
+		case !submit.Upsolve:
+			if submit.CreatedAT.Before(until) {
+				list = append(list, submit)
+			}
+		case submit.Virtual && submit.UID == v.UID:
+			at := v.ContestTime(contest, submit.CreatedAT)
+			if at.Before(contest.StartAt) || !at.Before(contest.EndAt) {
+				continue
+			}
+			submit.CreatedAT = at
+			submit.Upsolve = false
+			list = append(list, submit)
+		}
+	}
+	return list
+}
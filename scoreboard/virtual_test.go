@@ -0,0 +1,44 @@
+package scoreboard
+
+import (
+	"testing"
+	"time"
+
+	"online_judge/JudgeServer/common"
+	"online_judge/JudgeServer/model"
+)
+
+func TestVirtualSubmits(t *testing.T) {
+	start := time.Date(2020, 1, 1, 10, 0, 0, 0, time.UTC)
+	contest := model.Contest{StartAt: start, EndAt: start.Add(2 * time.Hour)}
+	v := model.VirtualParticipation{UID: "v", StartAt: start.Add(24 * time.Hour)}
+	submit := func(id int64, uid string, at time.Time, upsolve, virtual bool) model.ContestSubmit {
+		return model.ContestSubmit{
+			Submit:  model.Submit{ID: id, UID: uid, PID: 1, Result: common.Accept, CreatedAT: at},
+			Upsolve: upsolve,
+			Virtual: virtual,
+		}
+	}
+	submits := []model.ContestSubmit{
+		submit(1, "a", start.Add(10*time.Minute), false, false),
+		submit(2, "b", start.Add(50*time.Minute), false, false),
+		submit(3, "c", start.Add(3*time.Hour), true, false),
+		submit(4, "v", v.StartAt.Add(20*time.Minute), true, true),
+		submit(5, "w", v.StartAt.Add(5*time.Minute), true, true),
+	}
+
+	list := VirtualSubmits(contest, v, submits, v.StartAt.Add(30*time.Minute))
+	if len(list) != 2 || list[0].ID != 1 || list[1].ID != 4 || list[1].Upsolve ||
+		!list[1].CreatedAT.Equal(start.Add(20*time.Minute)) {
+		t.Errorf("unexpected submits during the participation: %+v", list)
+	}
+	standings := ICPC(contest, []int{1}, list, nil, false)
+	if len(standings) != 2 || standings[1].UID != "v" || standings[1].Penalty != 20 {
+		t.Errorf("unexpected virtual standings: %+v", standings)
+	}
+
+	list = VirtualSubmits(contest, v, submits, v.StartAt.Add(5*time.Hour))
+	if len(list) != 3 {
+		t.Errorf("unexpected submits after the participation: %+v", list)
+	}
+}
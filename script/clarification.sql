@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS `clarification` (
+  `id` INT NOT NULL AUTO_INCREMENT COMMENT 'primary key',
+  `cid` INT NOT NULL COMMENT 'contest ID',
+  `pid` INT NOT NULL DEFAULT 0 COMMENT 'problem ID, 0 for the whole contest',
+  `uid` VARCHAR(100) NOT NULL DEFAULT '' COMMENT 'asker, empty for announcements',
+  `entrant` VARCHAR(100) NOT NULL DEFAULT '' COMMENT 'contestant of the asker, the uid or team-<team id>',
+  `question` TEXT NOT NULL COMMENT 'question',
+  `answer` TEXT NOT NULL COMMENT 'answer, or the text of the announcement',
+  `answered_by` VARCHAR(100) NOT NULL DEFAULT '' COMMENT 'judge',
+  `public` TINYINT NOT NULL DEFAULT 0 COMMENT 'shown to every contestant',
+  `announcement` TINYINT NOT NULL DEFAULT 0 COMMENT 'announcement of the judges',
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '修改时间',
+  PRIMARY KEY (`id`),
+  KEY (`cid`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `clarification_read` (
+  `cid` INT NOT NULL COMMENT 'contest ID',
+  `uid` VARCHAR(100) NOT NULL COMMENT 'reader',
+  `read_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'last time the clarifications were read',
+  PRIMARY KEY (`cid`, `uid`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -3,9 +3,18 @@ CREATE TABLE IF NOT EXISTS `contest` (
     `title` VARCHAR(200) NOT NULL COMMENT 'contest title',
     `encrypt` int NOT NULL DEFAULT 0 COMMENT '1: public 2: private 3: password',
     `start_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '比赛开始时间',
-    `end_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '比赛结束时间',
+    `end_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '比赛结束时间',
+    `pretest` TINYINT NOT NULL DEFAULT 0 COMMENT 'judge on pretests only until the system test',
+    `mode` VARCHAR(20) NOT NULL DEFAULT "icpc" COMMENT 'standings mode, value: icpc, marathon, oi, ioi',
+    `freeze_minutes` INT NOT NULL DEFAULT 0 COMMENT 'the scoreboard is frozen the last minutes, 0: never',
+    `unfrozen` TINYINT NOT NULL DEFAULT 0 COMMENT 'the frozen scoreboard is revealed',
+    `password` VARCHAR(64) NOT NULL DEFAULT '' COMMENT 'hashed password of password contests',
+    `invite_code` VARCHAR(64) NOT NULL DEFAULT '' COMMENT 'invitation code of private contests, empty: none',
     `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
     `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '修改时间',
     PRIMARY KEY (`id`),
     UNIQUE KEY (`title`)
-) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
\ No newline at end of file
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- end_at used to be reset on every update of the contest.
+ALTER TABLE `contest` MODIFY `end_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '比赛结束时间';
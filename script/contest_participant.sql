@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS `contest_participant` (
+  `id` INT NOT NULL AUTO_INCREMENT COMMENT 'primary key',
+  `cid` INT NOT NULL COMMENT 'contest ID',
+  `uid` VARCHAR(100) NOT NULL COMMENT 'participant',
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY (`cid`, `uid`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
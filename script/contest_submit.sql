@@ -1,7 +1,8 @@
 CREATE TABLE IF NOT EXISTS `contest_submit` (
   `id`   INT NOT NULL AUTO_INCREMENT COMMENT 'primary key',
    `cid` INT NOT NULL COMMENT 'contest id',
-  `uid`  VARCHAR(100) NOT NULL COMMENT 'user id',
+  `uid`  VARCHAR(100) NOT NULL COMMENT 'entrant: user id, or team-<team id>',
+  `author` VARCHAR(100) NOT NULL DEFAULT '' COMMENT 'account who submitted',
   `pid`  INT NOT NULL COMMENT 'problem ID',
   `submit_id` VARCHAR(22) NOT NULL COMMENT 'submit ID',
   `result` VARCHAR(20) NOT NULL DEFAULT "waiting" COMMENT 'value: Accept, WrongAnswer, Time_limit, MemoryLimit,MemoryLimit,RuntimeError,SystemError, PresentationError, InternalError',
@@ -9,6 +10,9 @@ CREATE TABLE IF NOT EXISTS `contest_submit` (
   `language` VARCHAR(20) NOT NULL COMMENT 'value: C, CPP, GO',
   `memory` INT NOT NULL DEFAULT 0 COMMENT 'Programs Use memory',
   `run_time` INT NOT NULL DEFAULT 0 COMMENT 'Programs run time',
+  `score` DOUBLE NOT NULL DEFAULT 0 COMMENT 'percentage of the tests scored',
+  `upsolve` TINYINT NOT NULL DEFAULT 0 COMMENT 'submitted after the end of the contest',
+  `virtual` TINYINT NOT NULL DEFAULT 0 COMMENT 'submitted during a virtual participation',
   `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
   `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '修改时间',
   PRIMARY KEY (`id`),
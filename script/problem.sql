@@ -1,6 +1,7 @@
 CREATE TABLE IF NOT EXISTS `problem` (
   `id` INT NOT NULL COMMENT 'problem id',
   `name` VARCHAR(256) NOT NULL COMMENT 'problem name',
+  `type` VARCHAR(20) NOT NULL DEFAULT "standard" COMMENT 'value: standard, output_only, optimization, sql',
   `author` VARCHAR(256) NOT NULL COMMENT 'author ID',
   `status` VARCHAR(100) DEFAULT NULL DEFAULT "open" COMMENT 'status: open, close',
   `difficulty` VARCHAR(100) NOT NULL DEFAULT "" COMMENT 'difficulty',
@@ -13,6 +14,12 @@ CREATE TABLE IF NOT EXISTS `problem` (
   `time_limit` INT NOT NULL COMMENT 'time limit',
   `memory_limit` INT NOT NULL COMMENT 'memory limit',
   `author_code` VARCHAR(1000) DEFAULT "" COMMENT 'author code',
+  `gen_script` VARCHAR(4000) NOT NULL DEFAULT "" COMMENT 'test generation script',
+  `signature` VARCHAR(500) NOT NULL DEFAULT "" COMMENT 'function signature of function problems',
+  `file_input` VARCHAR(100) NOT NULL DEFAULT "" COMMENT 'input file name of file I/O problems, stdin if empty',
+  `file_output` VARCHAR(100) NOT NULL DEFAULT "" COMMENT 'output file name of file I/O problems, stdout if empty',
+  `objective` VARCHAR(10) NOT NULL DEFAULT "" COMMENT 'value: max, min. set for optimization problems',
+  `ordered_result` TINYINT NOT NULL DEFAULT 0 COMMENT 'compare the rows of sql problems in order',
   `created_time` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
   `updated_time` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '修改时间',
   PRIMARY KEY (`id`)
@@ -5,6 +5,9 @@ CREATE TABLE IF NOT EXISTS `problem_data` (
   `output_file` varchar(100) NOT NULL COMMENT "output file path",
   `md5` VARCHAR(100) NOT NULL COMMENT "",
   `md5_trim_space` VARCHAR(100) NOT NULL COMMENT "",
+  `is_sample` TINYINT NOT NULL DEFAULT 0 COMMENT "sample test, shown in the problem detail",
+  `is_pretest` TINYINT NOT NULL DEFAULT 0 COMMENT "pretest, judged during the contest",
+  `subtask` INT NOT NULL DEFAULT 0 COMMENT "subtask, its tests only score when all of them pass",
   PRIMARY KEY (id),
   UNIQUE KEY (input_file),
   UNIQUE KEY (output_file)
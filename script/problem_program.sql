@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS `problem_program` (
+  `id`  INT NOT NULL AUTO_INCREMENT COMMENT 'primary key',
+  `pid` INT NOT NULL COMMENT 'problem id',
+  `name` VARCHAR(100) NOT NULL COMMENT 'program name, used by the generation script',
+  `type` VARCHAR(20) NOT NULL COMMENT 'value: generator, validator, solution, grader, header',
+  `language` VARCHAR(20) NOT NULL COMMENT 'value: C, CPP, GO',
+  `code` TEXT NOT NULL COMMENT 'program code',
+  `tag` VARCHAR(10) NOT NULL DEFAULT "" COMMENT 'expected outcome of a solution: AC, WA, TLE, MLE, RE, FAIL',
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '修改时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY (`pid`, `name`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
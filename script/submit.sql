@@ -9,6 +9,7 @@ CREATE TABLE IF NOT EXISTS `submit` (
   `language` VARCHAR(20) NOT NULL COMMENT 'value: C, CPP, GO',
   `memory` INT NOT NULL DEFAULT 0 COMMENT 'Programs Use memory',
   `run_time` INT NOT NULL DEFAULT 0 COMMENT 'Programs run time',
+  `score` DOUBLE NOT NULL DEFAULT 0 COMMENT 'percentage of the tests scored',
   `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
   `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '修改时间',
   PRIMARY KEY (`id`),
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS `submit_output` (
+  `id`  INT NOT NULL AUTO_INCREMENT COMMENT 'primary key',
+  `submit_id` VARCHAR(22) NOT NULL COMMENT 'submit ID',
+  `case_index` INT NOT NULL COMMENT 'test case index',
+  `status` VARCHAR(20) NOT NULL COMMENT 'test case result',
+  `output` BLOB NOT NULL COMMENT 'truncated stdout',
+  `stderr` BLOB NOT NULL COMMENT 'truncated stderr',
+  `diff_pos` BIGINT NOT NULL DEFAULT -1 COMMENT 'offset of the first differing byte',
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  PRIMARY KEY (`id`),
+  KEY (`submit_id`),
+  KEY (`created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS `submit_score` (
+  `id` INT NOT NULL AUTO_INCREMENT COMMENT 'primary key',
+  `submit_id` VARCHAR(22) NOT NULL COMMENT 'submit ID',
+  `pid` INT NOT NULL COMMENT 'problem ID',
+  `data_id` INT NOT NULL COMMENT 'problem_data ID of the test case',
+  `accepted` TINYINT NOT NULL DEFAULT 0 COMMENT 'the output was accepted by the checker',
+  `raw` DOUBLE NOT NULL DEFAULT 0 COMMENT 'score printed by the checker',
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY (`submit_id`, `data_id`),
+  KEY (`pid`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
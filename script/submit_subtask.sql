@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS `submit_subtask` (
+  `id` INT NOT NULL AUTO_INCREMENT COMMENT 'primary key',
+  `submit_id` VARCHAR(22) NOT NULL COMMENT 'submit ID',
+  `pid` INT NOT NULL COMMENT 'problem ID',
+  `subtask` INT NOT NULL COMMENT 'subtask of the problem data, 0 for the tests without subtask',
+  `score` DOUBLE NOT NULL COMMENT 'score in percentage of the problem',
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY (`submit_id`, `subtask`),
+  KEY (`pid`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
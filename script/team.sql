@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS `team` (
+  `id` INT NOT NULL AUTO_INCREMENT COMMENT 'primary key',
+  `name` VARCHAR(100) NOT NULL COMMENT 'team name',
+  `created_by` VARCHAR(100) NOT NULL COMMENT 'creator account',
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '修改时间',
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `team_member` (
+  `id` INT NOT NULL AUTO_INCREMENT COMMENT 'primary key',
+  `team_id` INT NOT NULL COMMENT 'team ID',
+  `uid` VARCHAR(100) NOT NULL COMMENT 'member account',
+  `accepted` TINYINT NOT NULL DEFAULT 0 COMMENT 'the invitation was accepted',
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY (`team_id`, `uid`),
+  KEY (`uid`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS `virtual_participation` (
+  `id` INT NOT NULL AUTO_INCREMENT COMMENT 'primary key',
+  `cid` INT NOT NULL COMMENT 'contest ID',
+  `uid` VARCHAR(100) NOT NULL COMMENT 'user id',
+  `start_at` TIMESTAMP NOT NULL COMMENT 'start of the personal window',
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY (`cid`, `uid`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,112 @@
+package stream
+
+import (
+	"fmt"
+	"sync"
+)
+
+// buffer is the number of events kept for a slow subscriber, the oldest one is dropped when full.
+const buffer = 16
+
+type Event struct {
+	Name string
+	Data interface{}
+}
+
+// Hub fans out the events published on a topic to its subscribers. It also counts the changes of
+// the topics published with Change, so that the values computed from them can be cached until the
+// next change.
+type Hub struct {
+	mu       sync.Mutex
+	subs     map[string]map[chan Event]bool
+	versions map[string]int64
+}
+
+func NewHub() *Hub {
+	return &Hub{
+		subs:     make(map[string]map[chan Event]bool),
+		versions: make(map[string]int64),
+	}
+}
+
+// Default is the hub of the server.
+var Default = NewHub()
+
+func SubmissionTopic(submitID string) string {
+	return "submission:" + submitID
+}
+
+func ContestTopic(cid int64) string {
+	return fmt.Sprintf("contest:%d", cid)
+}
+
+// ClarificationTopic is kept apart from ContestTopic, clarifications do not change the standings.
+func ClarificationTopic(cid int64) string {
+	return fmt.Sprintf("clarification:%d", cid)
+}
+
+// Subscribe returns the events of the topic, cancel must be called once the events are not read.
+func (h *Hub) Subscribe(topic string) (<-chan Event, func()) {
+	ch := make(chan Event, buffer)
+	h.mu.Lock()
+	if h.subs[topic] == nil {
+		h.subs[topic] = make(map[chan Event]bool)
+	}
+	h.subs[topic][ch] = true
+	h.mu.Unlock()
+	cancel := func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		delete(h.subs[topic], ch)
+		if len(h.subs[topic]) == 0 {
+			delete(h.subs, topic)
+		}
+	}
+	return ch, cancel
+}
+
+// Publish sends the event to the subscribers of the topic without blocking.
+func (h *Hub) Publish(topic string, e Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.publish(topic, e)
+}
+
+// Change counts a change of the topic and publishes the event. The count is kept for the life of
+// the hub, it is only used for the topics of contests.
+func (h *Hub) Change(topic string, e Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.versions[topic]++
+	h.publish(topic, e)
+}
+
+func (h *Hub) publish(topic string, e Event) {
+	for ch := range h.subs[topic] {
+		select {
+		case ch <- e:
+			continue
+		default:
+		}
+		select {
+		case <-ch:
+		default:
+		}
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
+
+// Version returns the number of changes of the topic.
+func (h *Hub) Version(topic string) int64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.versions[topic]
+}
+
+// ContestChanged notifies the standings streams of the contest that its submissions changed.
+func ContestChanged(cid int64) {
+	Default.Change(ContestTopic(cid), Event{Name: "change"})
+}
@@ -0,0 +1,29 @@
+package stream
+
+import "testing"
+
+func TestHub(t *testing.T) {
+	hub := NewHub()
+	events, cancel := hub.Subscribe("a")
+	for i := 0; i < buffer+2; i++ {
+		hub.Change("a", Event{Name: "n", Data: i})
+	}
+	hub.Publish("b", Event{})
+	if v := hub.Version("a"); v != buffer+2 {
+		t.Errorf("expect version %d, but got %d", buffer+2, v)
+	}
+	if v := hub.Version("b"); v != 0 || len(hub.versions) != 1 {
+		t.Errorf("expect no version of published topics, but got %d", v)
+	}
+	if e := <-events; e.Data != 2 {
+		t.Errorf("expect the oldest events dropped, but got %v", e.Data)
+	}
+	for i := 3; i < buffer+2; i++ {
+		<-events
+	}
+	cancel()
+	hub.Publish("a", Event{})
+	if len(events) != 0 || len(hub.subs) != 0 {
+		t.Errorf("expect no event after cancel")
+	}
+}
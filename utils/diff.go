@@ -0,0 +1,78 @@
+package utils
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// maxDiffLines is the max number of differing lines reported by LineDiff.
+const maxDiffLines = 10
+
+// LineDiff compares expected and actual line by line and describes the differing lines.
+func LineDiff(expected, actual string) string {
+	var (
+		exp   = strings.Split(strings.TrimRight(expected, "\n"), "\n")
+		act   = strings.Split(strings.TrimRight(actual, "\n"), "\n")
+		diffs = make([]string, 0)
+	)
+	for i := 0; i < len(exp) || i < len(act); i++ {
+		var e, a = "<EOF>", "<EOF>"
+		if i < len(exp) {
+			e = exp[i]
+		}
+		if i < len(act) {
+			a = act[i]
+		}
+		if e == a {
+			continue
+		}
+		if len(diffs) == maxDiffLines {
+			diffs = append(diffs, "...")
+			break
+		}
+		diffs = append(diffs, fmt.Sprintf("line %d:\n- %s\n+ %s", i+1, e, a))
+	}
+	return strings.Join(diffs, "\n")
+}
+
+// FirstDifference returns the offset of the first differing byte of the two files, -1 if they are equal.
+func FirstDifference(file1, file2 string) (int64, error) {
+	f1, err := os.Open(file1)
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	defer f1.Close()
+	f2, err := os.Open(file2)
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	defer f2.Close()
+
+	var (
+		r1  = bufio.NewReader(f1)
+		r2  = bufio.NewReader(f2)
+		pos int64
+	)
+	for {
+		b1, err1 := r1.ReadByte()
+		b2, err2 := r2.ReadByte()
+		if err1 == io.EOF && err2 == io.EOF {
+			return -1, nil
+		}
+		if err1 != nil && err1 != io.EOF {
+			return 0, errors.WithStack(err1)
+		}
+		if err2 != nil && err2 != io.EOF {
+			return 0, errors.WithStack(err2)
+		}
+		if err1 != nil || err2 != nil || b1 != b2 {
+			return pos, nil
+		}
+		pos++
+	}
+}
@@ -0,0 +1,91 @@
+package utils
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+// issues found by LintData.
+const (
+	LintBOM             = "BOM"
+	LintCRLF            = "CRLF line endings"
+	LintNoFinalNewline  = "missing trailing newline"
+	LintTrailingSpace   = "trailing spaces"
+	LintTrailingNewline = "extra blank lines at the end"
+)
+
+var bom = []byte{0xEF, 0xBB, 0xBF}
+
+// LintReport lists the issues of one test data file.
+type LintReport struct {
+	File       string   `json:"file"`
+	Issues     []string `json:"issues"`
+	Normalized bool     `json:"normalized"`
+}
+
+// LintData returns the format issues of test data that commonly cause Presentation Errors.
+func LintData(data []byte) []string {
+	issues := make([]string, 0)
+	if bytes.HasPrefix(data, bom) {
+		issues = append(issues, LintBOM)
+		data = data[len(bom):]
+	}
+	if bytes.Contains(data, []byte("\r")) {
+		issues = append(issues, LintCRLF)
+	}
+	if len(data) == 0 {
+		return issues
+	}
+	if data[len(data)-1] != '\n' {
+		issues = append(issues, LintNoFinalNewline)
+	} else if bytes.HasSuffix(bytes.Replace(data, []byte("\r"), nil, -1), []byte("\n\n")) {
+		issues = append(issues, LintTrailingNewline)
+	}
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		line = bytes.TrimSuffix(line, []byte("\r"))
+		if len(line) != 0 && (line[len(line)-1] == ' ' || line[len(line)-1] == '\t') {
+			issues = append(issues, LintTrailingSpace)
+			break
+		}
+	}
+	return issues
+}
+
+// NormalizeData removes the BOM, converts line endings to LF, strips trailing spaces and blank
+// lines at the end, and makes sure non empty data ends with a newline.
+func NormalizeData(data []byte) []byte {
+	data = bytes.TrimPrefix(data, bom)
+	data = bytes.Replace(data, []byte("\r\n"), []byte("\n"), -1)
+	data = bytes.Replace(data, []byte("\r"), []byte("\n"), -1)
+	lines := bytes.Split(data, []byte("\n"))
+	for i := range lines {
+		lines[i] = bytes.TrimRight(lines[i], " \t")
+	}
+	data = bytes.TrimRight(bytes.Join(lines, []byte("\n")), "\n")
+	if len(data) == 0 {
+		return data
+	}
+	return append(data, '\n')
+}
+
+// LintFile lints the file and rewrites it normalized when normalize is true and issues are found.
+func LintFile(fileName string, normalize bool) (*LintReport, error) {
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	report := &LintReport{
+		File:   fileName,
+		Issues: LintData(data),
+	}
+	if normalize && len(report.Issues) != 0 {
+		if err := ioutil.WriteFile(fileName, NormalizeData(data), os.ModePerm); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		report.Normalized = true
+	}
+	return report, nil
+}
@@ -0,0 +1,30 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLintData(t *testing.T) {
+	cases := []struct {
+		data   string
+		issues []string
+		normal string
+	}{
+		{"1 2\n3\n", []string{}, "1 2\n3\n"},
+		{"\xEF\xBB\xBF1 2\r\n3", []string{LintBOM, LintCRLF, LintNoFinalNewline}, "1 2\n3\n"},
+		{"1 2 \n3\t\n\n", []string{LintTrailingNewline, LintTrailingSpace}, "1 2\n3\n"},
+		{"", []string{}, ""},
+	}
+	for _, c := range cases {
+		if issues := LintData([]byte(c.data)); !reflect.DeepEqual(issues, c.issues) {
+			t.Errorf("%q: expect issues %v, but result is %v", c.data, c.issues, issues)
+		}
+		if normal := string(NormalizeData([]byte(c.data))); normal != c.normal {
+			t.Errorf("%q: expect %q, but result is %q", c.data, c.normal, normal)
+		}
+		if issues := LintData([]byte(c.normal)); len(issues) != 0 {
+			t.Errorf("%q: normalized data still has issues %v", c.data, issues)
+		}
+	}
+}